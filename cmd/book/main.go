@@ -0,0 +1,132 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"runtime"
+	"time"
+
+	"github.com/eisenwinter/checkers/game"
+)
+
+func main() {
+	pdnDir := flag.String("pdn", "", "directory with PDN files (*.pdn) whose games are added to the book")
+	search := flag.Int("search", 0, "add this many plies of deep searched openings to the book")
+	depth := flag.Int("depth", 4, "search depth used to score the moves with -search")
+	width := flag.Int("width", 3, "maximum number of moves per position with -search")
+	margin := flag.Int("margin", 20, "moves scoring at most this much worse than the best move are kept with -search")
+	weights := flag.String("weights", "default", "evaluation weights with -search, a profile or a JSON file")
+	workers := flag.Int("workers", runtime.NumCPU(), "number of positions searched in parallel")
+	plies := flag.Int("plies", 16, "number of plies of every PDN game added to the book")
+	minWeight := flag.Int("min", 1, "moves with a lower weight are left out of the book")
+	out := flag.String("out", "book.txt", "file the book is written to")
+	probe := flag.String("probe", "", "list the book moves of a position given as FEN instead of building")
+	bookFile := flag.String("book", "book.txt", "book file to probe")
+	verbose := flag.Bool("v", false, "log the progress of -search")
+	flag.Parse()
+
+	if *probe != "" {
+		board, player, err := game.ParseFEN(*probe)
+		if err != nil {
+			fail(err)
+		}
+		book, err := game.LoadBook(*bookFile)
+		if err != nil {
+			fail(err)
+		}
+		moves := book.Moves(board, player)
+		if len(moves) == 0 {
+			fail(errors.New("the position is not in the book"))
+		}
+		for _, m := range moves {
+			fmt.Printf("%s %d\n", m.Move, m.Weight)
+		}
+		return
+	}
+
+	if *pdnDir == "" && *search <= 0 {
+		fail(errors.New("nothing to build from, pass -pdn or -search"))
+	}
+	began := time.Now()
+	book := game.NewBook()
+	if *pdnDir != "" {
+		games, err := addGames(book, *pdnDir, *plies)
+		if err != nil {
+			fail(err)
+		}
+		fmt.Printf("%d games read from %s\n", games, *pdnDir)
+	}
+	if *search > 0 {
+		w, err := game.LoadWeights(*weights)
+		if err != nil {
+			fail(err)
+		}
+		if !*verbose {
+			log.SetOutput(io.Discard)
+		}
+		book.Merge(game.SearchBook(game.BookSearchOptions{
+			Plies:   *search,
+			Depth:   *depth,
+			Width:   *width,
+			Margin:  *margin,
+			Workers: *workers,
+			Weights: &w,
+		}))
+		log.SetOutput(os.Stderr)
+	}
+	book.Prune(*minWeight)
+
+	f, err := os.Create(*out)
+	if err != nil {
+		fail(err)
+	}
+	if err := book.Encode(f); err != nil {
+		f.Close()
+		fail(err)
+	}
+	if err := f.Close(); err != nil {
+		fail(err)
+	}
+	fmt.Printf("book with %d positions written to %s (%s), play with it using -book %s\n", book.Len(), *out, time.Since(began).Round(time.Second), *out)
+}
+
+//addGames adds the games of all PDN files in the directory and returns their number
+func addGames(book *game.Book, dir string, plies int) (int, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.pdn"))
+	if err != nil {
+		return 0, err
+	}
+	if len(paths) == 0 {
+		return 0, fmt.Errorf("no PDN files in %s", dir)
+	}
+	//replaying the games logs every move
+	log.SetOutput(io.Discard)
+	defer log.SetOutput(os.Stderr)
+	count := 0
+	for _, path := range paths {
+		f, err := os.Open(path)
+		if err != nil {
+			return count, err
+		}
+		games, err := game.ReadPDN(f)
+		f.Close()
+		if err != nil {
+			return count, fmt.Errorf("%s: %w", path, err)
+		}
+		for _, g := range games {
+			book.AddGame(g, plies)
+		}
+		count += len(games)
+	}
+	return count, nil
+}
+
+func fail(err error) {
+	fmt.Fprintln(os.Stderr, err)
+	os.Exit(2)
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/eisenwinter/checkers/game"
+)
+
+//initialPosition is the starting position of international draughts
+const initialPosition = "W:W31-50:B1-20"
+
+func main() {
+	depth := flag.Int("depth", 6, "search depth in plies")
+	fen := flag.String("fen", initialPosition, "position in the FEN format")
+	divide := flag.Bool("divide", false, "split the node count by root move")
+	verify := flag.Bool("verify", false, "compare the starting position up to depth with the published numbers")
+	flag.Parse()
+
+	if *verify {
+		if !verifyPerft(*depth) {
+			os.Exit(1)
+		}
+		return
+	}
+
+	board, player, err := game.ParseFEN(*fen)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	start := time.Now()
+	total := uint64(0)
+	if *divide {
+		for _, s := range game.PerftDivide(board, player, *depth) {
+			fmt.Printf("%-16s %d\n", s.Move, s.Nodes)
+			total += s.Nodes
+		}
+	} else {
+		total = game.Perft(board, player, *depth)
+	}
+	fmt.Printf("perft(%d) = %d (%s)\n", *depth, total, time.Since(start).Round(time.Millisecond))
+}
+
+//verifyPerft checks the starting position against the published numbers
+func verifyPerft(depth int) bool {
+	board, player, err := game.ParseFEN(initialPosition)
+	if err != nil {
+		panic(err)
+	}
+	ok := true
+	for d := 1; d <= depth && d < len(game.KnownPerft); d++ {
+		start := time.Now()
+		nodes := game.Perft(board, player, d)
+		status := "ok"
+		if nodes != game.KnownPerft[d] {
+			status = fmt.Sprintf("FAILED expected %d", game.KnownPerft[d])
+			ok = false
+		}
+		fmt.Printf("perft(%d) = %d %s (%s)\n", d, nodes, status, time.Since(start).Round(time.Millisecond))
+	}
+	return ok
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"math/rand"
+	"os"
+	"strings"
+
+	"github.com/eisenwinter/checkers/game"
+)
+
+func main() {
+	positions := flag.Int("positions", 5000, "number of random positions to check")
+	seed := flag.Int64("seed", 1, "seed of the random positions")
+	weights := flag.String("weights", "", "weights to check, a profile or a JSON file (default all profiles)")
+	flag.Parse()
+	log.SetOutput(io.Discard)
+
+	names := game.WeightProfiles()
+	if *weights != "" {
+		names = []string{*weights}
+	}
+	all := make([]game.Weights, len(names))
+	for i, name := range names {
+		w, err := game.LoadWeights(name)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(2)
+		}
+		all[i] = w
+	}
+
+	rng := rand.New(rand.NewSource(*seed))
+	failures := 0
+	for i := 0; i < *positions; i++ {
+		//half of the positions come from random games, the other half are random placements
+		var board game.Board
+		var fen string
+		if i%2 == 0 {
+			board, fen = playedPosition(rng)
+		} else {
+			board, fen = game.RandomPosition(rng)
+		}
+		for j, w := range all {
+			if err := game.CheckEvaluationSymmetry(board, &w); err != nil {
+				failures++
+				if failures <= 10 {
+					fmt.Printf("%s (%s): %v\n", fen, names[j], err)
+				}
+			}
+		}
+	}
+	if failures > 0 {
+		fmt.Printf("%d of %d checks failed\n", failures, *positions*len(all))
+		os.Exit(1)
+	}
+	fmt.Printf("%d positions evaluate symmetrically with %s\n", *positions, strings.Join(names, ", "))
+}
+
+//playedPosition plays random moves from the starting position
+func playedPosition(rng *rand.Rand) (game.Board, string) {
+	g := game.SetupGame()
+	g.Start()
+	plies := rng.Intn(80)
+	for i := 0; i < plies && g.GameState() == game.GameStateRunning; i++ {
+		moves := g.GetPossibleMoves()
+		g.MakeMove(moves[rng.Intn(len(moves))].Move)
+	}
+	return g.CurrentBoard(), g.FEN()
+}
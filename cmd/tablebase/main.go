@@ -0,0 +1,61 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"runtime"
+	"time"
+
+	"github.com/eisenwinter/checkers/game"
+)
+
+func main() {
+	pieces := flag.Int("pieces", 3, "generate all positions with up to this many pieces, three kings against a king needs 4 which takes a long time")
+	out := flag.String("out", "tablebase.gz", "file the tablebase is written to")
+	workers := flag.Int("workers", runtime.NumCPU(), "number of goroutines searching the positions")
+	probe := flag.String("probe", "", "look up a position given as FEN instead of generating")
+	tablebase := flag.String("tb", "tablebase.gz", "tablebase file to probe")
+	flag.Parse()
+
+	if *probe != "" {
+		board, player, err := game.ParseFEN(*probe)
+		if err != nil {
+			fail(err)
+		}
+		t, err := game.LoadTablebase(*tablebase)
+		if err != nil {
+			fail(err)
+		}
+		r, ok := t.Probe(board, player)
+		if !ok {
+			fail(fmt.Errorf("the position has more than %d pieces", t.Pieces))
+		}
+		fmt.Println(r)
+		return
+	}
+
+	if *pieces < 2 {
+		fail(errors.New("a tablebase needs at least 2 pieces"))
+	}
+	began := time.Now()
+	t := game.GenerateTablebase(*pieces, *workers)
+	f, err := os.Create(*out)
+	if err != nil {
+		fail(err)
+	}
+	if err := t.Encode(f); err != nil {
+		f.Close()
+		fail(err)
+	}
+	if err := f.Close(); err != nil {
+		fail(err)
+	}
+	fmt.Printf("tablebase with up to %d pieces written to %s (%s), play with it using -tablebase %s\n", *pieces, *out, time.Since(began).Round(time.Second), *out)
+}
+
+func fail(err error) {
+	fmt.Fprintln(os.Stderr, err)
+	os.Exit(2)
+}
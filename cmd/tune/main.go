@@ -0,0 +1,106 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/eisenwinter/checkers/game"
+)
+
+func main() {
+	positionsFile := flag.String("positions", "positions.txt", "labeled positions, one FEN and PDN result (2-0, 1-1, 0-2) per line")
+	generate := flag.Int("generate", 0, "play this many self play games first and append their positions to the positions file")
+	depth := flag.Int("depth", 2, "search depth of the self play engines")
+	randomPlies := flag.Int("random-plies", 8, "random plies at the start of each self play game")
+	maxPlies := flag.Int("max-plies", 300, "self play games longer than this are scored as a draw")
+	seed := flag.Int64("seed", 1, "seed of the self play games")
+	workers := flag.Int("workers", runtime.NumCPU(), "number of self play games played in parallel")
+	start := flag.String("start", "default", "weights to start from, a profile or a JSON file")
+	out := flag.String("out", "weights.json", "file the tuned weights are written to")
+	iterations := flag.Int("iterations", 0, "maximum number of tuning passes, 0 runs until no weight improves")
+	fixed := flag.String("fixed", "", "comma separated weights that are not tuned, e.g. piece,winningMove")
+	verbose := flag.Bool("v", false, "log the self play games")
+	flag.Parse()
+
+	weights, err := game.LoadWeights(*start)
+	if err != nil {
+		fail(err)
+	}
+	if *generate > 0 {
+		if !*verbose {
+			log.SetOutput(io.Discard)
+		}
+		began := time.Now()
+		positions := game.SelfPlay(game.SelfPlayOptions{
+			Games:       *generate,
+			Depth:       *depth,
+			RandomPlies: *randomPlies,
+			MaxPlies:    *maxPlies,
+			Seed:        *seed,
+			Workers:     *workers,
+			Weights:     &weights,
+		})
+		log.SetOutput(os.Stderr)
+		if err := appendPositions(*positionsFile, positions); err != nil {
+			fail(err)
+		}
+		fmt.Printf("%d positions from %d games written to %s (%s)\n", len(positions), *generate, *positionsFile, time.Since(began).Round(time.Second))
+	}
+
+	f, err := os.Open(*positionsFile)
+	if err != nil {
+		fail(err)
+	}
+	positions, err := game.ReadLabeledPositions(f)
+	f.Close()
+	if err != nil {
+		fail(fmt.Errorf("%s: %w", *positionsFile, err))
+	}
+
+	opts := game.TuneOptions{Iterations: *iterations}
+	if *fixed != "" {
+		opts.Fixed = strings.Split(*fixed, ",")
+	}
+	result, err := game.Tune(positions, weights, opts)
+	if err != nil {
+		fail(err)
+	}
+	fmt.Printf("error %.6f -> %.6f (K %.4f)\n", result.StartError, result.Error, result.K)
+
+	w, err := os.Create(*out)
+	if err != nil {
+		fail(err)
+	}
+	if err := result.Weights.WriteJSON(w); err != nil {
+		w.Close()
+		fail(err)
+	}
+	if err := w.Close(); err != nil {
+		fail(err)
+	}
+	fmt.Printf("weights written to %s, play with them using -weights %s\n", *out, *out)
+}
+
+//appendPositions adds the positions to the end of the file, creating it if needed
+func appendPositions(path string, positions []game.LabeledPosition) error {
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	if err := game.WriteLabeledPositions(f, positions); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+func fail(err error) {
+	fmt.Fprintln(os.Stderr, err)
+	os.Exit(2)
+}
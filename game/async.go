@@ -0,0 +1,136 @@
+package game
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+)
+
+//aiResultBuffer is the capacity of the channel returned by StartAIMove
+const aiResultBuffer = 16
+
+//ErrGameOver is returned when an ai move is requested for a finished game
+var ErrGameOver = errors.New("game is over")
+
+//AIResult is sent while an ai move is searched, progress updates carry the finished search iterations
+//and the final result (Done) the chosen move or the error
+type AIResult struct {
+	Move Move
+	Info SearchInfo
+	Err  error
+	Done bool
+	//position is the hash of the position the move was searched for
+	position uint64
+}
+
+//aiSearch holds everything an engine needs, so the search can run without touching the game
+type aiSearch struct {
+	engine Engine
+	board  Board
+	player bool
+	draws  drawCounter
+}
+
+//prepareSearch captures the current position for the engine of the current player
+func (g *Game) prepareSearch() (aiSearch, error) {
+	e := g.Engine(g.player)
+	if e == nil {
+		return aiSearch{}, fmt.Errorf("no engine set for the current player")
+	}
+	return aiSearch{e, g.board.copy(), g.player, g.draws}, nil
+}
+
+//position returns the hash of the searched position
+func (a aiSearch) position() uint64 {
+	return a.board.hash(a.player)
+}
+
+//run asks the engine for a move and makes sure its legal
+func (a aiSearch) run(ctx context.Context) (Move, SearchInfo, error) {
+	var m Move
+	var info SearchInfo
+	var err error
+	if d, ok := a.engine.(drawAwareEngine); ok {
+		m, info, err = d.bestMove(ctx, a.board, a.player, a.draws)
+	} else {
+		m, info, err = a.engine.BestMove(ctx, a.board, a.player)
+	}
+	if err != nil {
+		return Move{}, info, err
+	}
+	legal, ok := legalMove(a.board, a.player, m)
+	if !ok {
+		return Move{}, info, fmt.Errorf("engine returned illegal move %s", m)
+	}
+	return legal, info, nil
+}
+
+//legalMove looks up the move in the possible moves of the player
+func legalMove(b Board, player bool, m Move) (Move, bool) {
+	id := m.identity()
+	for _, v := range b.getPossibleValidMovesForPlayer(player) {
+		if v.identity() == id {
+			return v, true
+		}
+	}
+	return Move{}, false
+}
+
+//StartAIMove searches a move for the current player on a goroutine without changing the game.
+//The channel receives progress updates and finally the result with Done set, then its closed.
+//The search stops when the context is cancelled, the move is played with PlayAIResult.
+func (g *Game) StartAIMove(ctx context.Context) <-chan AIResult {
+	results := make(chan AIResult, aiResultBuffer)
+	if g.GameState() != GameStateRunning {
+		results <- AIResult{Err: ErrGameOver, Done: true}
+		close(results)
+		return results
+	}
+	a, err := g.prepareSearch()
+	if err != nil {
+		results <- AIResult{Err: err, Done: true}
+		close(results)
+		return results
+	}
+	go func() {
+		defer close(results)
+		//progress is dropped when the reader falls behind, the last slot is kept for the result
+		progress := func(info SearchInfo) {
+			if len(results) < cap(results)-1 {
+				results <- AIResult{Move: info.Move, Info: info, position: a.position()}
+			}
+		}
+		m, info, err := a.run(WithSearchProgress(ctx, progress))
+		results <- AIResult{Move: m, Info: info, Err: err, Done: true, position: a.position()}
+	}()
+	return results
+}
+
+//PlayAIResult plays the move of a finished ai search,
+//it fails if the search failed or the game moved on since the search was started
+func (g *Game) PlayAIResult(r AIResult) error {
+	if r.Err != nil {
+		return r.Err
+	}
+	if !r.Done {
+		return fmt.Errorf("ai search is not finished yet")
+	}
+	if g.GameState() != GameStateRunning {
+		return ErrGameOver
+	}
+	if r.position != g.board.hash(g.player) {
+		return fmt.Errorf("ai move %s was searched for another position", r.Move)
+	}
+	info := r.Info
+	if info.FromBook {
+		log.Printf("AI move: %s | book", r.Move)
+	} else {
+		log.Printf("AI move: %s | depth: %d | score: %d | pv: %s | nodes: %d (%d/s) | time: %s | cutoffs: %d (%d by first move)",
+			r.Move, info.Depth, info.Score, info.PVString(), info.Nodes, info.NodesPerSecond, info.Elapsed.Round(time.Millisecond), info.Cutoffs, info.FirstMoveCutoffs)
+	}
+	g.redo = g.redo[:0]
+	g.playMove(r.Move)
+	return nil
+}
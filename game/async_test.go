@@ -0,0 +1,122 @@
+package game
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log"
+	"testing"
+	"time"
+)
+
+//chattyEngine reports many progress updates before it plays the first move
+type chattyEngine struct {
+	updates int
+	//reported is closed once all updates are sent
+	reported chan struct{}
+}
+
+func (e *chattyEngine) BestMove(ctx context.Context, board Board, player bool) (Move, SearchInfo, error) {
+	m := board.getPossibleValidMovesForPlayer(player)[0]
+	for i := 0; i < e.updates; i++ {
+		reportProgress(ctx, SearchInfo{Move: m, Depth: i + 1})
+	}
+	close(e.reported)
+	return m, SearchInfo{Move: m, Depth: e.updates}, nil
+}
+
+//aiGame sets up a running game with the engine playing both sides
+func aiGame(e Engine) *Game {
+	g := SetupGame()
+	g.Start()
+	g.SetEngine(true, e)
+	g.SetEngine(false, e)
+	return g
+}
+
+//finalResult reads the results until the channel is closed and returns the progress count and the last result
+func finalResult(t *testing.T, results <-chan AIResult) (int, AIResult) {
+	progress := 0
+	var last AIResult
+	timeout := time.After(10 * time.Second)
+	for {
+		select {
+		case r, ok := <-results:
+			if !ok {
+				return progress, last
+			}
+			if !r.Done {
+				progress++
+			}
+			last = r
+		case <-timeout:
+			t.Fatal("no result after 10s")
+		}
+	}
+}
+
+func TestStartAIMoveCancel(t *testing.T) {
+	g := aiGame(&MinimaxEngine{Depth: maxSearchDepth})
+	ctx, cancel := context.WithCancel(context.Background())
+	results := g.StartAIMove(ctx)
+	//the first iteration always finishes, the search is cancelled while it goes deeper
+	if r := <-results; r.Done {
+		t.Fatalf("search finished before it was cancelled: %v", r.Err)
+	}
+	cancel()
+	_, r := finalResult(t, results)
+	if !r.Done || !errors.Is(r.Err, context.Canceled) {
+		t.Fatalf("cancelled search ended with %v (done %v)", r.Err, r.Done)
+	}
+	if err := g.PlayAIResult(r); err == nil || len(g.History()) != 0 {
+		t.Error("the result of a cancelled search was played")
+	}
+}
+
+func TestStartAIMoveFullBuffer(t *testing.T) {
+	e := &chattyEngine{updates: 10 * aiResultBuffer, reported: make(chan struct{})}
+	g := aiGame(e)
+	results := g.StartAIMove(context.Background())
+	//nobody reads while the engine reports
+	select {
+	case <-e.reported:
+	case <-time.After(10 * time.Second):
+		t.Fatal("the engine blocked on a full progress buffer")
+	}
+	progress, r := finalResult(t, results)
+	if progress == 0 || progress >= aiResultBuffer {
+		t.Errorf("%d progress updates delivered, want some but fewer than %d", progress, aiResultBuffer)
+	}
+	if !r.Done || r.Err != nil || r.Info.Depth != e.updates {
+		t.Fatalf("final result %+v, want the move after %d updates", r, e.updates)
+	}
+	defer log.SetOutput(log.Writer())
+	log.SetOutput(io.Discard)
+	if err := g.PlayAIResult(r); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestPlayAIResultRejectsStaleResult(t *testing.T) {
+	defer log.SetOutput(log.Writer())
+	log.SetOutput(io.Discard)
+	g := aiGame(&MinimaxEngine{Depth: 2})
+	_, r := finalResult(t, g.StartAIMove(context.Background()))
+	if r.Err != nil {
+		t.Fatal(r.Err)
+	}
+	//the game moves on before the result is played
+	g.MakeMove(g.GetPossibleMoves()[0].Move)
+	fen := g.FEN()
+	if err := g.PlayAIResult(r); err == nil {
+		t.Error("a result searched for another position was played")
+	}
+	if g.FEN() != fen || len(g.History()) != 1 {
+		t.Error("rejecting the result changed the game")
+	}
+	//taking the move back makes the result fit again
+	g.Undo()
+	if err := g.PlayAIResult(r); err != nil {
+		t.Error(err)
+	}
+}
@@ -1,5 +1,12 @@
 package game
 
+import (
+	"fmt"
+	"math/rand"
+	"strconv"
+	"strings"
+)
+
 var height = 10
 var width = 10
 
@@ -245,6 +252,19 @@ func (m Move) allTakedowns() []Coordinate {
 	return c
 }
 
+//CaptureCount returns the number of pieces the move captures
+func (m Move) CaptureCount() int {
+	return len(m.allTakedowns())
+}
+
+//origin returns the coordinate the moving piece started from
+func (m Move) origin() Coordinate {
+	if m.Previous != nil {
+		return m.Previous.origin()
+	}
+	return m.From
+}
+
 func (m Move) pathway() []Coordinate {
 	c := make([]Coordinate, 0)
 	if m.Previous != nil {
@@ -441,28 +461,30 @@ func (b Board) getMoveType(from, to Coordinate, player bool) (MoveType, Coordina
 	return InvalidMove, Coordinate{}
 }
 
+//lineOfSightSkip returns the captures of a king in the given direction, the king may fly over empty squares
+//to the piece it takes and land on any empty square behind it, the capture continues from each of those squares
 func (b Board) lineOfSightSkip(dir func(Coordinate) (bool, Coordinate), pos Coordinate, player bool, prev *Move) []Move {
 	m := make([]Move, 0)
-	for ok, current := dir(pos); ok; ok, current = dir(current) {
-		if mt, cord := b.getMoveType(pos, current, player); mt != InvalidMove {
-			move := Move{pos, cord, nil, nil, 0}
-			if prev != nil {
-				move.Previous = prev
-				move.Depth = prev.Depth + 1
-			}
-			if mt == SkipMove {
-				tmp := current.clone()
-				move.Takes = &tmp
-				m = append(m, move)
-
-				nextBoard := boardForNextSkip(b, pos, cord, *move.Takes, player)
-				nextMoves := nextBoard.getPossibleSkipsFor(cord, player, &move)
-				for _, v := range nextMoves {
-					m = append(m, v)
-				}
-				return m
-			}
+	ok, taken := dir(pos)
+	for ok && b.must(taken).isEmpty() {
+		ok, taken = dir(taken)
+	}
+	//own pieces and pieces already taken during this move block the line
+	if !ok || b.must(taken).isWhitePiece() == player || b.must(taken).isMarked() {
+		return m
+	}
+	for ok, landing := dir(taken); ok && b.must(landing).isEmpty(); ok, landing = dir(landing) {
+		depth := 0
+		if prev != nil {
+			depth = prev.Depth + 1
 		}
+		tmp := taken.clone()
+		move := Move{pos, landing, &tmp, prev, depth}
+		m = append(m, move)
+
+		nextBoard := boardForNextSkip(b, pos, landing, taken, player)
+		nextMoves := nextBoard.getPossibleSkipsFor(landing, player, &move)
+		m = append(m, nextMoves...)
 	}
 	return m
 }
@@ -514,29 +536,13 @@ func (b Board) getPossibleSkipsFor(pos Coordinate, player bool, prev *Move) []Mo
 	return m
 }
 
+//lineOfSightMoves returns the moves of a king in the given direction including captures
 func (b Board) lineOfSightMoves(dir func(Coordinate) (bool, Coordinate), pos Coordinate, player bool) []Move {
 	m := make([]Move, 0)
-	for ok, current := dir(pos); ok; ok, current = dir(current) {
-		if mt, cord := b.getMoveType(pos, current, player); mt != InvalidMove {
-			move := Move{pos, cord, nil, nil, 0}
-			if mt == SkipMove {
-				tmp := current.clone()
-				move.Takes = &tmp
-				m = append(m, move)
-
-				nextBoard := boardForNextSkip(b, pos, cord, *move.Takes, player)
-				nextMoves := nextBoard.getPossibleSkipsFor(cord, player, &move)
-				for _, v := range nextMoves {
-					m = append(m, v)
-				}
-				return m
-			}
-			m = append(m, move)
-		} else {
-			return m
-		}
+	for ok, current := dir(pos); ok && b.must(current).isEmpty(); ok, current = dir(current) {
+		m = append(m, Move{pos, current, nil, nil, 0})
 	}
-	return m
+	return append(m, b.lineOfSightSkip(dir, pos, player, nil)...)
 }
 
 func boardForNextSkip(b Board, from, to, taken Coordinate, player bool) Board {
@@ -610,25 +616,51 @@ func (b Board) getPossibleMoves(pos Coordinate, player bool) []Move {
 	return m
 }
 
-//filterMoves prunes any non must moves when must moves are in the list
+//filterMoves prunes any non must moves when must moves are in the list,
+//only the moves capturing the most pieces are kept and captures that only differ in their path are dropped
 func filterMoves(move []Move) []Move {
-	highestDepth := 0
-	take := false
+	most := 0
 	for _, v := range move {
-		highestDepth = maxOf(v.Depth, highestDepth)
-		if v.Takes != nil {
-			take = true
-		}
+		most = maxOf(v.CaptureCount(), most)
 	}
 	filtered := make([]Move, 0)
 	for _, v := range move {
-		if highestDepth == v.Depth {
-			if !take || (take && v.Takes != nil) {
-				filtered = append(filtered, v)
-			}
+		if v.CaptureCount() == most {
+			filtered = append(filtered, v)
+		}
+	}
+	return uniqueMoves(filtered)
+}
+
+//moveIdentity identifies a move by its start, end and captured pieces,
+//different capture paths with the same identity are the same move
+type moveIdentity struct {
+	from     int
+	to       int
+	captured [2]uint64
+}
+
+func (m Move) identity() moveIdentity {
+	id := moveIdentity{from: m.origin().ToIndex(), to: m.To.ToIndex()}
+	for _, c := range m.allTakedowns() {
+		i := c.ToIndex()
+		id.captured[i/64] |= 1 << (i % 64)
+	}
+	return id
+}
+
+//uniqueMoves removes moves that only differ in the capture path
+func uniqueMoves(move []Move) []Move {
+	seen := make(map[moveIdentity]bool)
+	unique := make([]Move, 0, len(move))
+	for _, v := range move {
+		id := v.identity()
+		if !seen[id] {
+			seen[id] = true
+			unique = append(unique, v)
 		}
 	}
-	return filtered
+	return unique
 }
 
 // getCounts retruns white piece count, red piece count, white king count and red king count
@@ -656,6 +688,27 @@ func (b Board) getCounts() (white int, red int, wking int, rking int) {
 	return white, red, wking, rking
 }
 
+//emptyBoard creates a board without any pieces
+func emptyBoard() Board {
+	board := make(Board, height*width)
+	for i := range board {
+		board[i] = set(0, Empty)
+	}
+	return board
+}
+
+//placePiece puts a piece of the player on the given position
+func (b Board) placePiece(pos Coordinate, player bool, king bool) {
+	f := Field(0)
+	if player {
+		f = set(f, Player)
+	}
+	if king {
+		f = set(f, King)
+	}
+	b[IndexOf(pos.Row, pos.Col)] = f
+}
+
 // boardSetup creates a starting board
 func boardSetup(board Board) Board {
 	for i := range board {
@@ -706,3 +759,29 @@ func debugSetFullGate(board Board) {
 	board[IndexOf(1, 4)] = clear(board[IndexOf(1, 4)], Empty)
 	board[IndexOf(2, 5)] = clear(board[IndexOf(2, 5)], Empty)
 }
+
+//RandomPosition puts a random number of men and kings of both colors on random squares
+//and returns the board together with its FEN (white to move)
+func RandomPosition(rng *rand.Rand) (Board, string) {
+	for {
+		white, red := make([]string, 0), make([]string, 0)
+		for _, n := range rng.Perm(squareCount)[:2+rng.Intn(squareCount/2)] {
+			square := n + 1
+			isWhite := rng.Intn(2) == 0
+			s := strconv.Itoa(square)
+			//men can not stand on their promotion row
+			if rng.Intn(5) == 0 || isWhite && square <= 5 || !isWhite && square > squareCount-5 {
+				s = "K" + s
+			}
+			if isWhite {
+				white = append(white, s)
+			} else {
+				red = append(red, s)
+			}
+		}
+		fen := fmt.Sprintf("W:W%s:B%s", strings.Join(white, ","), strings.Join(red, ","))
+		if b, _, err := ParseFEN(fen); err == nil {
+			return b, fen
+		}
+	}
+}
@@ -0,0 +1,395 @@
+package game
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"math/rand"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+//BookMove is a candidate move of a book position, moves are chosen proportionally to their weight
+type BookMove struct {
+	//Move is the move in numeric notation, e.g. 32-28
+	Move   string
+	Weight int
+}
+
+//Book is an opening book mapping positions (by their zobrist hash) to candidate moves
+type Book struct {
+	mu        sync.Mutex
+	positions map[uint64][]BookMove
+}
+
+//NewBook creates an empty book
+func NewBook() *Book {
+	return &Book{positions: make(map[uint64][]BookMove)}
+}
+
+//Len returns the number of positions in the book
+func (b *Book) Len() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return len(b.positions)
+}
+
+//Add adds the weight to the move of the position, the move is added if it is not in the book yet
+func (b *Book) Add(board Board, player bool, m Move, weight int) {
+	b.add(board.hash(player), m.String(), weight)
+}
+
+func (b *Book) add(key uint64, move string, weight int) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	moves := b.positions[key]
+	for i := range moves {
+		if moves[i].Move == move {
+			moves[i].Weight += weight
+			return
+		}
+	}
+	b.positions[key] = append(moves, BookMove{move, weight})
+}
+
+//Moves returns the book moves of the position ordered by weight, moves that are not legal in the position are left out
+func (b *Book) Moves(board Board, player bool) []BookMove {
+	if b == nil {
+		return nil
+	}
+	b.mu.Lock()
+	entries := b.positions[board.hash(player)]
+	b.mu.Unlock()
+	moves := make([]BookMove, 0, len(entries))
+	for _, e := range entries {
+		//a hash collision or a broken book must not lead to an illegal move
+		if _, err := ParseMove(board, player, e.Move); err == nil && e.Weight > 0 {
+			moves = append(moves, e)
+		}
+	}
+	sort.SliceStable(moves, func(i, j int) bool {
+		return moves[i].Weight > moves[j].Weight
+	})
+	return moves
+}
+
+//Choose picks a book move of the position, with rng by weight, without rng the move with the highest weight.
+//False if the position is not in the book.
+func (b *Book) Choose(board Board, player bool, rng *rand.Rand) (Move, bool) {
+	moves := b.Moves(board, player)
+	if len(moves) == 0 {
+		return Move{}, false
+	}
+	chosen := moves[0]
+	if rng != nil {
+		total := 0
+		for _, m := range moves {
+			total += m.Weight
+		}
+		r := rng.Intn(total)
+		for _, m := range moves {
+			if r < m.Weight {
+				chosen = m
+				break
+			}
+			r -= m.Weight
+		}
+	}
+	m, err := ParseMove(board, player, chosen.Move)
+	return m, err == nil
+}
+
+//Merge adds all moves of the other book
+func (b *Book) Merge(o *Book) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	for key, moves := range o.positions {
+		for _, m := range moves {
+			b.add(key, m.Move, m.Weight)
+		}
+	}
+}
+
+//Prune removes moves with less than minWeight and the positions left without moves
+func (b *Book) Prune(minWeight int) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for key, moves := range b.positions {
+		kept := moves[:0]
+		for _, m := range moves {
+			if m.Weight >= minWeight {
+				kept = append(kept, m)
+			}
+		}
+		if len(kept) == 0 {
+			delete(b.positions, key)
+		} else {
+			b.positions[key] = kept
+		}
+	}
+}
+
+//AddGame adds the first plies moves of the PDN game to the book. Every move counts 1,
+//moves of the side that won the game by its Result tag count 2, as a resigned game is not over on the board.
+func (b *Book) AddGame(p PDNGame, plies int) {
+	g := p.Game
+	result, ok := p.Tags["Result"]
+	if !ok {
+		result = pdnResult(g.state)
+	}
+	winner := pdnWinner(result)
+	board, player := g.startBoard.copy(), g.startPlayer
+	for i, m := range g.History() {
+		if i >= plies {
+			break
+		}
+		weight := 1
+		if (winner == GameStateWhiteWins && player) || (winner == GameStateRedWins && !player) {
+			weight = 2
+		}
+		b.Add(board, player, m, weight)
+		unrollMove(&board, m, player, m.Depth)
+		player = !player
+	}
+}
+
+//Encode writes the book as text, a line per position with the hash followed by the moves and their weights,
+//e.g. `0f3a9c2e4b1d7a65 32-28:12 33-29:5`
+func (b *Book) Encode(w io.Writer) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	keys := make([]uint64, 0, len(b.positions))
+	for key := range b.positions {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i] < keys[j] })
+	out := bufio.NewWriter(w)
+	for _, key := range keys {
+		fmt.Fprintf(out, "%016x", key)
+		for _, m := range b.positions[key] {
+			fmt.Fprintf(out, " %s:%d", m.Move, m.Weight)
+		}
+		if _, err := fmt.Fprintln(out); err != nil {
+			return err
+		}
+	}
+	return out.Flush()
+}
+
+//ReadBook reads a book written by Encode, empty lines and lines starting with # are skipped
+func ReadBook(r io.Reader) (*Book, error) {
+	b := NewBook()
+	scanner := bufio.NewScanner(r)
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		fields := strings.Fields(text)
+		key, err := strconv.ParseUint(fields[0], 16, 64)
+		if err != nil || len(fields) < 2 {
+			return nil, fmt.Errorf("book line %d: expected a position hash and moves", line)
+		}
+		for _, f := range fields[1:] {
+			i := strings.LastIndex(f, ":")
+			if i < 0 {
+				return nil, fmt.Errorf("book line %d: move %q has no weight", line, f)
+			}
+			weight, err := strconv.Atoi(f[i+1:])
+			if err != nil {
+				return nil, fmt.Errorf("book line %d: move %q: %w", line, f, err)
+			}
+			b.add(key, f[:i], weight)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+//LoadBook reads the book file at path
+func LoadBook(path string) (*Book, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return ReadBook(f)
+}
+
+//BookSearchOptions configures building a book by searching the opening positions
+type BookSearchOptions struct {
+	//Plies is the depth of the book in plies
+	Plies int
+	//Depth is the search depth used to score every candidate move
+	Depth int
+	//Width is the maximum number of moves kept per position
+	Width int
+	//Margin keeps moves scoring at most this much worse than the best move
+	Margin int
+	//Workers is the number of positions searched in parallel
+	Workers int
+	//Weights are the evaluation weights, nil means DefaultWeights
+	Weights *Weights
+}
+
+//SearchBook builds a book from the starting position by searching every move of a position and
+//keeping the best ones, whose replies are searched in turn until the book is Plies deep.
+//A kept move is weighted by how close it scored to the best move.
+func SearchBook(opts BookSearchOptions) *Book {
+	book := NewBook()
+	type position struct {
+		board  Board
+		player bool
+	}
+	level := []position{{boardSetup(make(Board, height*width)), true}}
+	seen := make(map[uint64]bool)
+	for ply := 0; ply < opts.Plies && len(level) > 0; ply++ {
+		next := make([][]position, len(level))
+		jobs := make(chan int, len(level))
+		for i := range level {
+			jobs <- i
+		}
+		close(jobs)
+		var wg sync.WaitGroup
+		for w := 0; w < maxOf(opts.Workers, 1); w++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for i := range jobs {
+					p := level[i]
+					//a fresh engine keeps the scores of a position independent of the positions searched before
+					e := &MinimaxEngine{Depth: opts.Depth, Weights: opts.Weights}
+					for _, m := range searchBookMoves(e, p.board, p.player, opts) {
+						book.Add(p.board, p.player, m.move, m.weight)
+						b := p.board.copy()
+						unrollMove(&b, m.move, p.player, m.move.Depth)
+						next[i] = append(next[i], position{b, !p.player})
+					}
+				}
+			}()
+		}
+		wg.Wait()
+		level = level[:0]
+		for _, positions := range next {
+			for _, p := range positions {
+				//transpositions are expanded once
+				if key := p.board.hash(p.player); !seen[key] {
+					seen[key] = true
+					level = append(level, p)
+				}
+			}
+		}
+		log.Printf("Book ply %d: %d positions to search next", ply+1, len(level))
+	}
+	return book
+}
+
+//weightedMove is a book candidate and its weight
+type weightedMove struct {
+	move   Move
+	weight int
+}
+
+//searchBookMoves scores every move of the position and returns the best ones within the margin
+func searchBookMoves(e *MinimaxEngine, board Board, player bool, opts BookSearchOptions) []weightedMove {
+	scored := scoreBookMoves(e, board, player)
+	if len(scored) == 0 {
+		return nil
+	}
+	best := scored[0].weight
+	kept := make([]weightedMove, 0, opts.Width)
+	for _, s := range scored {
+		if len(kept) >= maxOf(opts.Width, 1) || best-s.weight > opts.Margin {
+			break
+		}
+		kept = append(kept, weightedMove{s.move, opts.Margin - (best - s.weight) + 1})
+	}
+	return kept
+}
+
+//scoreBookMoves searches the position after every move and returns the moves with their scores
+//from the perspective of the player, the best first
+func scoreBookMoves(e *MinimaxEngine, board Board, player bool) []weightedMove {
+	moves := board.getPossibleValidMovesForPlayer(player)
+	scored := make([]weightedMove, 0, len(moves))
+	for _, m := range moves {
+		b := board.copy()
+		unrollMove(&b, m, player, m.Depth)
+		score := lossScore(!player)
+		//a forced reply is searched as deep as the others, BestMove would only evaluate it
+		if replies := b.getPossibleValidMovesForPlayer(!player); len(replies) > 0 {
+			_, info, _ := e.deepen(context.Background(), b, !player, drawCounter{}, replies, time.Now())
+			score = info.Score
+		}
+		//wins and losses count the move to the searched position
+		score = distanceScore(score, 1)
+		//scores are from the perspective of the player to move
+		if !player {
+			score = -score
+		}
+		scored = append(scored, weightedMove{m, score})
+	}
+	sort.SliceStable(scored, func(i, j int) bool { return scored[i].weight > scored[j].weight })
+	return scored
+}
+
+//BookEngine plays book moves while the position is in the book and lets Engine search otherwise.
+//The book is turned off by setting Disabled or leaving Book nil.
+type BookEngine struct {
+	Engine Engine
+	Book   *Book
+	//Disabled skips the book and always searches
+	Disabled bool
+	//Seed chooses among the book moves by weight, 0 always plays the move with the highest weight
+	Seed int64
+
+	mu  sync.Mutex
+	rng *rand.Rand
+}
+
+//NewBookEngine wraps the engine with the book
+func NewBookEngine(e Engine, book *Book, seed int64) *BookEngine {
+	return &BookEngine{Engine: e, Book: book, Seed: seed}
+}
+
+//BestMove plays a book move or searches with the wrapped engine
+func (e *BookEngine) BestMove(ctx context.Context, board Board, player bool) (Move, SearchInfo, error) {
+	return e.bestMove(ctx, board, player, drawCounter{})
+}
+
+func (e *BookEngine) bestMove(ctx context.Context, board Board, player bool, draws drawCounter) (Move, SearchInfo, error) {
+	if err := ctx.Err(); err != nil {
+		return Move{}, SearchInfo{}, err
+	}
+	if m, ok := e.bookMove(board, player); ok {
+		//book moves are not scored, FromBook tells them apart
+		return m, SearchInfo{Move: m, PV: []Move{m}, FromBook: true}, nil
+	}
+	if d, ok := e.Engine.(drawAwareEngine); ok {
+		return d.bestMove(ctx, board, player, draws)
+	}
+	return e.Engine.BestMove(ctx, board, player)
+}
+
+//bookMove chooses the book move of the position, false if the book is off or the position is not in it
+func (e *BookEngine) bookMove(board Board, player bool) (Move, bool) {
+	if e.Disabled || e.Book == nil {
+		return Move{}, false
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.Seed != 0 && e.rng == nil {
+		e.rng = rand.New(rand.NewSource(e.Seed))
+	}
+	return e.Book.Choose(board, player, e.rng)
+}
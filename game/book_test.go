@@ -0,0 +1,136 @@
+package game
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"log"
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+//resignedGame is a PDN game red resigned, the board still shows a running game
+const resignedGame = `[Event "test"]
+[Result "0-2"]
+1. 32-28 19-23 2. 28x19 14x23 0-2
+`
+
+func TestBookAddGameUsesResult(t *testing.T) {
+	//replaying the game logs every move
+	defer log.SetOutput(log.Writer())
+	log.SetOutput(io.Discard)
+	games, err := ReadPDN(strings.NewReader(resignedGame))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if games[0].Game.GameState() != GameStateRunning {
+		t.Fatalf("the resigned game is %v on the board", games[0].Game.GameState())
+	}
+	book := NewBook()
+	book.AddGame(games[0], 4)
+	start := boardSetup(make(Board, height*width))
+	//white lost, red won by the result tag
+	if moves := book.Moves(start, true); len(moves) != 1 || moves[0].Weight != 1 {
+		t.Errorf("white move: %v, want weight 1", moves)
+	}
+	m, _ := ParseMove(start, true, "32-28")
+	next := start.copy()
+	unrollMove(&next, m, true, m.Depth)
+	if moves := book.Moves(next, false); len(moves) != 1 || moves[0].Weight != 2 {
+		t.Errorf("red move: %v, want weight 2", moves)
+	}
+}
+
+func TestBookRoundTrip(t *testing.T) {
+	start := boardSetup(make(Board, height*width))
+	book := NewBook()
+	for _, move := range []struct {
+		text   string
+		weight int
+	}{{"32-28", 6}, {"33-28", 3}, {"31-27", 1}} {
+		m, err := ParseMove(start, true, move.text)
+		if err != nil {
+			t.Fatal(err)
+		}
+		book.Add(start, true, m, move.weight)
+	}
+	var buf bytes.Buffer
+	if err := book.Encode(&buf); err != nil {
+		t.Fatal(err)
+	}
+	read, err := ReadBook(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, got := book.Moves(start, true), read.Moves(start, true)
+	if len(got) != len(want) {
+		t.Fatalf("read %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("read %v, want %v", got, want)
+		}
+	}
+
+	//without a generator the heaviest move is played
+	if m, ok := read.Choose(start, true, nil); !ok || m.String() != "32-28" {
+		t.Errorf("chose %s, want 32-28", m)
+	}
+	//with a generator the moves are chosen by weight
+	rng := rand.New(rand.NewSource(1))
+	counts := make(map[string]int)
+	const draws = 10000
+	for i := 0; i < draws; i++ {
+		m, _ := read.Choose(start, true, rng)
+		counts[m.String()]++
+	}
+	for _, m := range want {
+		expected := draws * m.Weight / 10
+		if c := counts[m.Move]; c < expected*8/10 || c > expected*12/10 {
+			t.Errorf("%s chosen %d times, expected about %d", m.Move, c, expected)
+		}
+	}
+}
+
+func TestBookEngine(t *testing.T) {
+	start := boardSetup(make(Board, height*width))
+	book := NewBook()
+	m, _ := ParseMove(start, true, "32-28")
+	book.Add(start, true, m, 1)
+	e := NewBookEngine(&MinimaxEngine{Depth: 1}, book, 0)
+	move, info, err := e.BestMove(context.Background(), start, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if move.String() != "32-28" || !info.FromBook || info.Score != 0 {
+		t.Errorf("played %s from book %v with score %d, want the unscored book move 32-28", move, info.FromBook, info.Score)
+	}
+}
+
+func TestScoreBookMovesSearchesForcedReplies(t *testing.T) {
+	const depth = 3
+	for _, fen := range []string{
+		//25x14 leaves red a single capture
+		"W:W9,25,28,31,32,34,39,42,44,46,50:B1,6,7,20,22",
+		//12-17 wins, the win has to count the move into the searched position
+		"B:W21,47:B1,4,12,13,15,37,K45",
+	} {
+		b, player, err := ParseFEN(fen)
+		if err != nil {
+			t.Fatal(err)
+		}
+		scored := scoreBookMoves(&MinimaxEngine{Depth: depth}, b, player)
+		//the parent searched one ply deeper scores its best move the same
+		_, info, err := (&MinimaxEngine{Depth: depth + 1}).BestMove(context.Background(), b, player)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !player {
+			info.Score = -info.Score
+		}
+		if scored[0].weight != info.Score {
+			t.Errorf("%s: best book move %s scores %d, the search %d", fen, scored[0].move, scored[0].weight, info.Score)
+		}
+	}
+}
@@ -0,0 +1,86 @@
+package game
+
+import "math"
+
+//kingMovesLimit is the number of plies (25 moves each) with only king moves
+//and no captures after which the game is a draw
+const kingMovesLimit = 50
+
+//endgameMovesLimit is the longest small endgame in plies, 16 moves each with three pieces against a lone king
+const endgameMovesLimit = 32
+
+//shortEndgameMovesLimit is the longest small endgame in plies, 5 moves each with two pieces against a lone king
+const shortEndgameMovesLimit = 10
+
+//drawCounter tracks the FMJD draw rules that depend on the moves played
+type drawCounter struct {
+	//kingMoves counts the consecutive plies where only kings moved without a capture
+	kingMoves int
+	//endgameMoves counts the plies played since the current small endgame arose
+	endgameMoves int
+	//endgamePieces is the piece count of the current small endgame (0 = none)
+	endgamePieces int
+}
+
+//next returns the counters after the move m was played,
+//b is the board after the move and kingMove indicates that a king was moved
+func (d drawCounter) next(b Board, m Move, kingMove bool) drawCounter {
+	if kingMove && m.Takes == nil {
+		d.kingMoves++
+	} else {
+		d.kingMoves = 0
+	}
+	pieces := b.smallEndgamePieces()
+	if pieces == 0 || pieces != d.endgamePieces {
+		d.endgameMoves = 0
+	} else {
+		d.endgameMoves++
+	}
+	d.endgamePieces = pieces
+	return d
+}
+
+//draw checks if one of the rules declares the game a draw and returns the reason
+func (d drawCounter) draw() (bool, string) {
+	if d.kingMoves >= kingMovesLimit {
+		return true, "25 moves with only kings and no capture, draw"
+	}
+	//three pieces against a lone king may play 16 moves each
+	if d.endgamePieces == 4 && d.endgameMoves >= endgameMovesLimit {
+		return true, "16 moves in a three against one king endgame, draw"
+	}
+	//two pieces against a lone king may play 5 moves each
+	if d.endgamePieces == 3 && d.endgameMoves >= shortEndgameMovesLimit {
+		return true, "5 moves in a two against one king endgame, draw"
+	}
+	return false, ""
+}
+
+//remaining returns the number of plies that can be played on the board before a rule declares the game a draw,
+//assuming no capture resets the counters. With men on the board a man move can reset the king moves.
+func (d drawCounter) remaining(b Board) int {
+	left := math.MaxInt
+	if w, r, wk, rk := b.getCounts(); w == wk && r == rk {
+		left = kingMovesLimit - d.kingMoves
+	}
+	switch d.endgamePieces {
+	case 4:
+		left = minOf(left, endgameMovesLimit-d.endgameMoves)
+	case 3:
+		left = minOf(left, shortEndgameMovesLimit-d.endgameMoves)
+	}
+	return left
+}
+
+//smallEndgamePieces returns the total piece count if the board is a small endgame
+//(a lone king against at most three pieces including a king), 0 otherwise
+func (b Board) smallEndgamePieces() int {
+	w, r, wk, rk := b.getCounts()
+	if w == 1 && wk == 1 && r <= 3 && rk >= 1 {
+		return w + r
+	}
+	if r == 1 && rk == 1 && w <= 3 && wk >= 1 {
+		return w + r
+	}
+	return 0
+}
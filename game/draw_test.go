@@ -0,0 +1,45 @@
+package game
+
+import "testing"
+
+func TestDrawRules(t *testing.T) {
+	tests := []struct {
+		name string
+		fen  string
+		//draws are the counters before the first move, the last move reaches the limit
+		draws drawCounter
+		moves []string
+		draw  bool
+		want  drawCounter
+	}{
+		{"25 king moves", "W:WK47,K49:BK2,K4", drawCounter{kingMoves: kingMovesLimit - 2},
+			[]string{"47-42", "2-7"}, true, drawCounter{kingMoves: kingMovesLimit}},
+		{"man move resets", "W:WK47,38:BK2,K4", drawCounter{kingMoves: kingMovesLimit - 2},
+			[]string{"38-33", "2-7"}, false, drawCounter{kingMoves: 1}},
+		{"capture resets", "W:WK47,K49:BK2,K4,33", drawCounter{kingMoves: kingMovesLimit - 1},
+			[]string{"47x29"}, false, drawCounter{}},
+		{"16 moves three against one", "W:WK47,K49,K50:BK2", drawCounter{endgameMoves: endgameMovesLimit - 2, endgamePieces: 4},
+			[]string{"47-42", "2-7"}, true, drawCounter{kingMoves: 2, endgameMoves: endgameMovesLimit, endgamePieces: 4}},
+		{"5 moves two against one", "W:WK47,K49:BK2", drawCounter{endgameMoves: shortEndgameMovesLimit - 2, endgamePieces: 3},
+			[]string{"47-42", "2-7"}, true, drawCounter{kingMoves: 2, endgameMoves: shortEndgameMovesLimit, endgamePieces: 3}},
+		//taking a piece starts the two against one endgame
+		{"capture starts another endgame", "W:WK47:BK2,K4,33", drawCounter{endgameMoves: endgameMovesLimit - 1, endgamePieces: 4},
+			[]string{"47x29"}, false, drawCounter{endgamePieces: 3}},
+	}
+	for _, tt := range tests {
+		g := startGame(t, tt.fen)
+		g.draws = tt.draws
+		playMoves(t, g, tt.moves[:len(tt.moves)-1]...)
+		if g.GameState() != GameStateRunning {
+			t.Errorf("%s: game ended a move before the limit: %s", tt.name, g.GameState())
+			continue
+		}
+		playMoves(t, g, tt.moves[len(tt.moves)-1])
+		if draw := g.GameState() == GameStateDraw; draw != tt.draw {
+			t.Errorf("%s: game is %s, draw %v wanted", tt.name, g.GameState(), tt.draw)
+		}
+		if g.draws != tt.want {
+			t.Errorf("%s: counters %+v, want %+v", tt.name, g.draws, tt.want)
+		}
+	}
+}
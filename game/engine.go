@@ -0,0 +1,112 @@
+package game
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"strings"
+	"sync"
+	"time"
+)
+
+//ErrNoMove is returned by an engine if the player has no legal move
+var ErrNoMove = errors.New("no legal move")
+
+//Engine searches a move for the player to move on the given board
+type Engine interface {
+	BestMove(ctx context.Context, board Board, player bool) (Move, SearchInfo, error)
+}
+
+//SearchInfo describes how an engine found its move
+type SearchInfo struct {
+	//Move is the best move found
+	Move Move
+	//PV is the principal variation, the line of moves the engine expects starting with Move
+	PV []Move
+	//Depth is the search depth in plies
+	Depth int
+	//Score is the evaluation of the move from whites perspective
+	Score int
+	//Nodes is the number of searched positions
+	Nodes uint64
+	//QuiescenceNodes is the part of the nodes searched beyond the depth to resolve captures
+	QuiescenceNodes uint64
+	//TableHits is the number of positions found in the transposition table
+	TableHits uint64
+	//TablebaseHits is the number of positions scored exactly by the endgame tablebase
+	TablebaseHits uint64
+	//Cutoffs is the number of positions where the search was pruned
+	Cutoffs uint64
+	//FirstMoveCutoffs is the number of cutoffs by the first searched move, a measure of the move ordering
+	FirstMoveCutoffs uint64
+	//Elapsed is the time spent searching
+	Elapsed time.Duration
+	//NodesPerSecond is the search speed
+	NodesPerSecond uint64
+	//FromBook is set if the move was taken from the opening book without searching
+	FromBook bool
+}
+
+//PVString returns the principal variation in numeric notation
+func (i SearchInfo) PVString() string {
+	moves := make([]string, 0, len(i.PV))
+	for _, m := range i.PV {
+		moves = append(moves, m.String())
+	}
+	return strings.Join(moves, " ")
+}
+
+//timed sets the elapsed time and the search speed
+func (i *SearchInfo) timed(start time.Time) {
+	i.Elapsed = time.Since(start)
+	if i.Elapsed > 0 {
+		i.NodesPerSecond = uint64(float64(i.Nodes) / i.Elapsed.Seconds())
+	}
+}
+
+//progressKey is the context key of the search progress callback
+type progressKey struct{}
+
+//WithSearchProgress returns a context that makes engines report every finished search iteration to fn,
+//fn is called on the searching goroutine
+func WithSearchProgress(ctx context.Context, fn func(SearchInfo)) context.Context {
+	return context.WithValue(ctx, progressKey{}, fn)
+}
+
+//reportProgress passes the search info to the progress callback of the context if there is one
+func reportProgress(ctx context.Context, info SearchInfo) {
+	if fn, ok := ctx.Value(progressKey{}).(func(SearchInfo)); ok {
+		fn(info)
+	}
+}
+
+//drawAwareEngine is implemented by engines that take the draw rule counters of the game into account
+type drawAwareEngine interface {
+	bestMove(ctx context.Context, board Board, player bool, draws drawCounter) (Move, SearchInfo, error)
+}
+
+//RandomEngine plays a random legal move, mostly useful for testing
+type RandomEngine struct {
+	mu  sync.Mutex
+	rng *rand.Rand
+}
+
+//NewRandomEngine creates a random mover with the given seed
+func NewRandomEngine(seed int64) *RandomEngine {
+	return &RandomEngine{rng: rand.New(rand.NewSource(seed))}
+}
+
+//BestMove picks a random legal move
+func (e *RandomEngine) BestMove(ctx context.Context, board Board, player bool) (Move, SearchInfo, error) {
+	if err := ctx.Err(); err != nil {
+		return Move{}, SearchInfo{}, err
+	}
+	moves := board.getPossibleValidMovesForPlayer(player)
+	if len(moves) == 0 {
+		return Move{}, SearchInfo{}, ErrNoMove
+	}
+	e.mu.Lock()
+	m := moves[e.rng.Intn(len(moves))]
+	e.mu.Unlock()
+	return m, SearchInfo{Move: m, PV: []Move{m}, Depth: 1, Score: board.evaluate()}, nil
+}
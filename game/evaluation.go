@@ -0,0 +1,130 @@
+package game
+
+import (
+	"fmt"
+	"strings"
+)
+
+//Evaluation is the score of a board broken down into its terms
+type Evaluation struct {
+	//Score is the evaluation from whites perspective, the sum of the term scores unless the game is decided
+	Score int
+	//Decided is set if a side has no pieces or moves left, the terms are empty then
+	Decided bool
+	Terms   []EvaluationTerm
+}
+
+//EvaluationTerm is a single evaluation term
+type EvaluationTerm struct {
+	Name string
+	//White and Red are the raw counts of each side, for move terms the values of the best move of each side
+	White int
+	Red   int
+	//Weight is the factor of the term, for move terms the term weight times the bestMove weight
+	Weight int
+	//Score is the contribution to the evaluation, (White - Red) * Weight
+	Score int
+}
+
+//EvaluateDetailed evaluates the board with the default weights and returns every term
+func EvaluateDetailed(b Board) Evaluation {
+	return EvaluateDetailedWith(b, &DefaultWeights)
+}
+
+//EvaluateDetailedWith evaluates the board with the given weights and returns every term,
+//the score is always the same as the one the engine uses
+func EvaluateDetailedWith(b Board, wt *Weights) Evaluation {
+	return b.evaluateTerms(wt, true)
+}
+
+//evaluateTerms is the evaluation used by the engine and the detailed evaluation.
+//Every board term adds its counts, every move term the values of the best move of each side weighted by
+//the term weight and bestMove. Unless detailed the terms are only summed up and terms weighted 0 are skipped.
+func (b Board) evaluateTerms(wt *Weights, detailed bool) Evaluation {
+	if score, ok := b.terminalScore(); ok {
+		return Evaluation{Score: score, Decided: true}
+	}
+	e := Evaluation{}
+	if detailed {
+		e.Terms = make([]EvaluationTerm, 0, len(boardTerms)+len(moveTerms))
+	}
+	for _, t := range boardTerms {
+		weight := *t.weight(wt)
+		if weight == 0 && !detailed {
+			continue
+		}
+		white, red := t.count(b)
+		e.add(t.name, white, red, weight, detailed)
+	}
+	if wt.BestMove == 0 && !detailed {
+		return e
+	}
+	white, red := b.bestMoveValues(wt)
+	for i, t := range moveTerms {
+		e.add(t.name, white[i], red[i], *t.weight(wt)*wt.BestMove, detailed)
+	}
+	return e
+}
+
+//add adds the score of a term, detailed evaluations keep the term
+func (e *Evaluation) add(name string, white int, red int, weight int, detailed bool) {
+	score := white*weight - red*weight
+	e.Score += score
+	if detailed {
+		e.Terms = append(e.Terms, EvaluationTerm{name, white, red, weight, score})
+	}
+}
+
+//String formats the evaluation as a table with a line per term
+func (e Evaluation) String() string {
+	if e.Decided {
+		if e.Score > 0 {
+			return fmt.Sprintf("score: %d (white wins)", e.Score)
+		}
+		return fmt.Sprintf("score: %d (red wins)", e.Score)
+	}
+	var s strings.Builder
+	fmt.Fprintf(&s, "%-16s %5s %5s %6s %6s\n", "term", "white", "red", "weight", "score")
+	for _, t := range e.Terms {
+		fmt.Fprintf(&s, "%-16s %5d %5d %6d %6d\n", t.Name, t.White, t.Red, t.Weight, t.Score)
+	}
+	fmt.Fprintf(&s, "%-16s %26d", "total", e.Score)
+	return s.String()
+}
+
+//Mirror returns the board turned by 180 degrees with the colors swapped,
+//the same position with the roles of white and red exchanged
+func (b Board) Mirror() Board {
+	m := make(Board, len(b))
+	for i, f := range b {
+		r, c := reverseIndexOf(i)
+		if !f.isEmpty() {
+			if has(f, Player) {
+				f = clear(f, Player)
+			} else {
+				f = set(f, Player)
+			}
+		}
+		m[IndexOf(height-1-r, width-1-c)] = f
+	}
+	return m
+}
+
+//CheckEvaluationSymmetry makes sure the evaluation does not favour a color,
+//the mirrored board has to score exactly the negated score with the counts of every term swapped
+func CheckEvaluationSymmetry(b Board, wt *Weights) error {
+	e := EvaluateDetailedWith(b, wt)
+	m := EvaluateDetailedWith(b.Mirror(), wt)
+	if e.Decided != m.Decided {
+		return fmt.Errorf("board is decided: %v, mirrored board is decided: %v", e.Decided, m.Decided)
+	}
+	for i, t := range e.Terms {
+		if mt := m.Terms[i]; t.White != mt.Red || t.Red != mt.White {
+			return fmt.Errorf("term %s counts white %d red %d, mirrored white %d red %d", t.Name, t.White, t.Red, mt.White, mt.Red)
+		}
+	}
+	if e.Score != -m.Score {
+		return fmt.Errorf("score %d, mirrored score %d", e.Score, m.Score)
+	}
+	return nil
+}
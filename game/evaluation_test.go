@@ -0,0 +1,177 @@
+package game
+
+import (
+	"math/rand"
+	"testing"
+)
+
+//randomPositions plays random moves from the starting position and returns the reached positions
+func randomPositions(seed int64, count int) ([]Board, []bool) {
+	rng := rand.New(rand.NewSource(seed))
+	boards, players := make([]Board, 0, count), make([]bool, 0, count)
+	for len(boards) < count {
+		b, player := boardSetup(make(Board, height*width)), true
+		plies := rng.Intn(90)
+		for i := 0; i < plies; i++ {
+			moves := b.getPossibleValidMovesForPlayer(player)
+			if len(moves) == 0 {
+				break
+			}
+			m := moves[rng.Intn(len(moves))]
+			unrollMove(&b, m, player, m.Depth)
+			player = !player
+		}
+		boards = append(boards, b)
+		players = append(players, player)
+	}
+	return boards, players
+}
+
+func TestEvaluateDetailedMatchesEvaluate(t *testing.T) {
+	boards, _ := randomPositions(1, 300)
+	for _, name := range WeightProfiles() {
+		wt, err := WeightProfile(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, b := range boards {
+			e := EvaluateDetailedWith(b, &wt)
+			if score := b.evaluateWith(&wt); e.Score != score {
+				t.Fatalf("%s (%s): detailed score %d, evaluation %d", FEN(b, true), name, e.Score, score)
+			}
+			sum := 0
+			for _, term := range e.Terms {
+				sum += term.Score
+			}
+			if !e.Decided && sum != e.Score {
+				t.Fatalf("%s (%s): terms add up to %d, score %d", FEN(b, true), name, sum, e.Score)
+			}
+			if f, ok := b.features(); ok && f.evaluate(&wt) != e.Score {
+				t.Fatalf("%s (%s): tuning features score %d, evaluation %d", FEN(b, true), name, f.evaluate(&wt), e.Score)
+			}
+		}
+	}
+	for _, b := range boards {
+		if EvaluateDetailed(b).Score != b.evaluate() {
+			t.Fatalf("%s: EvaluateDetailed differs from evaluate", FEN(b, true))
+		}
+	}
+}
+
+func TestEvaluateDetailedReportsMoveTerms(t *testing.T) {
+	e := EvaluateDetailed(boardSetup(make(Board, height*width)))
+	names := make(map[string]bool)
+	for _, term := range e.Terms {
+		names[term.Name] = true
+	}
+	for _, t2 := range moveTerms {
+		if !names[t2.name] {
+			t.Errorf("move term %s is not reported", t2.name)
+		}
+	}
+}
+
+//placedPositions returns random placements of men and kings
+func placedPositions(seed int64, count int) []Board {
+	rng := rand.New(rand.NewSource(seed))
+	boards := make([]Board, count)
+	for i := range boards {
+		boards[i], _ = RandomPosition(rng)
+	}
+	return boards
+}
+
+func TestEvaluationSymmetry(t *testing.T) {
+	played, _ := randomPositions(2, 1000)
+	boards := append(played, placedPositions(3, 1000)...)
+	for _, name := range WeightProfiles() {
+		wt, err := WeightProfile(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, b := range boards {
+			if err := CheckEvaluationSymmetry(b, &wt); err != nil {
+				t.Fatalf("%s (%s): %v", FEN(b, true), name, err)
+			}
+		}
+	}
+}
+
+func TestHeuristics(t *testing.T) {
+	tests := []struct {
+		name       string
+		fen        string
+		count      func(Board) (int, int)
+		white, red int
+	}{
+		//a lone man in the middle has all neighbours free, a man on the edge is a leggard
+		{"leggard", "W:W28:B3", Board.getLeggardAndGrapeCount, 0, 1},
+		//both men stand on the left side of their player
+		{"left side", "W:W36:B15", Board.getLeftSideCount, 1, 1},
+		{"right side", "W:W36:B15", Board.getRightSideCount, 0, 0},
+		//every capture of a piece counts once
+		{"vulnerable", "W:W32,33:B28", Board.getVulnerablePiecesCount, 2, 2},
+		//both moves of either side walk into a capture
+		{"suicidal", "W:W37:B27", Board.getSuicidalPiecesCount, 2, 2},
+	}
+	for _, tt := range tests {
+		b, _, err := ParseFEN(tt.fen)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if white, red := tt.count(b); white != tt.white || red != tt.red {
+			t.Errorf("%s %s: got %d/%d, want %d/%d", tt.name, tt.fen, white, red, tt.white, tt.red)
+		}
+	}
+}
+
+func TestMoveHeuristics(t *testing.T) {
+	tests := []struct {
+		name      string
+		fen       string
+		move      string
+		heuristic func(Board, Move, bool) bool
+		want      bool
+	}{
+		//the capture chain starts on the threatened square 32
+		{"saving", "W:W32:B27,17", "32x21x12", heuristicSavingMove, true},
+		//the king passes but does not leave a square a red capture lands on
+		{"loose protection", "W:WK24,K5,K27:BK10,33,38,K21", "5x32x43", heuristicLooseProtectingMove, false},
+		//red promotes, the heuristic is asked for the moving side
+		{"king move", "B:W33:B42", "42-47", heuristicMoveLeadsToKing, true},
+	}
+	for _, tt := range tests {
+		b, player, err := ParseFEN(tt.fen)
+		if err != nil {
+			t.Fatal(err)
+		}
+		m, err := ParseMove(b, player, tt.move)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := tt.heuristic(b, m, player); got != tt.want {
+			t.Errorf("%s %s %s: got %v, want %v", tt.name, tt.fen, tt.move, got, tt.want)
+		}
+	}
+	//move terms ask the heuristics for the side that moves
+	b, _, _ := ParseFEN("B:W33:B42")
+	m, _ := ParseMove(b, false, "42-47")
+	values := make([]int, len(moveTerms))
+	if b.moveValues(m, false, nil, values); values[2] != 1 {
+		t.Errorf("red promotion is not counted as king move: %v", values)
+	}
+}
+
+func TestTerminalScore(t *testing.T) {
+	//both sides are blocked, who loses depends on the side to move
+	b, _, err := ParseFEN("W:W46,47,48,49,50:B36,37,38,39,40,41,42,43,44,45")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, decided := b.terminalScore(); decided {
+		t.Error("a board where both sides are stuck is decided")
+	}
+	if lossScore(true) != -lossScore(false) {
+		t.Error("loss scores of both sides do not mirror")
+	}
+}
@@ -0,0 +1,131 @@
+package game
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+//ParseFEN parses a position in the draughts FEN format (e.g. `W:W31,32,K45:B1,2,K3`)
+//and returns the board and the player to move (true = white)
+func ParseFEN(fen string) (Board, bool, error) {
+	fen = strings.TrimSuffix(strings.TrimSpace(fen), ".")
+	parts := strings.Split(fen, ":")
+	if len(parts) != 3 {
+		return nil, false, fmt.Errorf("fen %q: expected 3 sections separated by ':'", fen)
+	}
+	var player bool
+	switch strings.TrimSpace(parts[0]) {
+	case "W":
+		player = true
+	case "B":
+		player = false
+	default:
+		return nil, false, fmt.Errorf("fen %q: invalid side to move %q", fen, parts[0])
+	}
+
+	b := emptyBoard()
+	seen := make(map[string]bool)
+	for _, section := range parts[1:] {
+		section = strings.TrimSpace(section)
+		if section == "" {
+			return nil, false, fmt.Errorf("fen %q: empty piece section", fen)
+		}
+		var white bool
+		switch section[0] {
+		case 'W':
+			white = true
+		case 'B':
+			white = false
+		default:
+			return nil, false, fmt.Errorf("fen %q: invalid color %q", fen, section[:1])
+		}
+		if seen[section[:1]] {
+			return nil, false, fmt.Errorf("fen %q: duplicate color %q", fen, section[:1])
+		}
+		seen[section[:1]] = true
+		if len(section) == 1 {
+			continue
+		}
+		for _, piece := range strings.Split(section[1:], ",") {
+			piece = strings.TrimSpace(piece)
+			king := strings.HasPrefix(piece, "K")
+			if king {
+				piece = piece[1:]
+			}
+			from, to, err := parseSquareRange(piece)
+			if err != nil {
+				return nil, false, fmt.Errorf("fen %q: %w", fen, err)
+			}
+			for n := from; n <= to; n++ {
+				_, c := CoordinateOfSquare(n)
+				if !b.must(c).isEmpty() {
+					return nil, false, fmt.Errorf("fen %q: square %d is occupied twice", fen, n)
+				}
+				if !king && b.isBoardEnd(c.Row, white) {
+					return nil, false, fmt.Errorf("fen %q: man on promotion square %d", fen, n)
+				}
+				b.placePiece(c, white, king)
+			}
+		}
+	}
+	return b, player, nil
+}
+
+//parseSquareRange parses a single square (`31`) or a range of squares (`31-35`)
+func parseSquareRange(s string) (int, int, error) {
+	bounds := strings.SplitN(s, "-", 2)
+	from, err := parseSquare(bounds[0])
+	if err != nil {
+		return 0, 0, err
+	}
+	to := from
+	if len(bounds) == 2 {
+		if to, err = parseSquare(bounds[1]); err != nil {
+			return 0, 0, err
+		}
+		if to < from {
+			return 0, 0, fmt.Errorf("invalid square range %q", s)
+		}
+	}
+	return from, to, nil
+}
+
+//parseSquare parses a square number (1-50)
+func parseSquare(s string) (int, error) {
+	n, err := strconv.Atoi(s)
+	if err != nil || n < 1 || n > squareCount {
+		return 0, fmt.Errorf("invalid square %q", s)
+	}
+	return n, nil
+}
+
+//FEN returns the position in the draughts FEN format
+func FEN(b Board, player bool) string {
+	var sb strings.Builder
+	if player {
+		sb.WriteString("W")
+	} else {
+		sb.WriteString("B")
+	}
+	for _, white := range []bool{true, false} {
+		if white {
+			sb.WriteString(":W")
+		} else {
+			sb.WriteString(":B")
+		}
+		squares := make([]string, 0)
+		pieces := b.allPiecesFor(white)
+		sort.Slice(pieces, func(i, j int) bool { return pieces[i].Square() < pieces[j].Square() })
+		for _, p := range pieces {
+			if b.must(p).isKing() {
+				squares = append(squares, fmt.Sprintf("K%d", p.Square()))
+			} else {
+				squares = append(squares, strconv.Itoa(p.Square()))
+			}
+		}
+		sb.WriteString(strings.Join(squares, ","))
+	}
+	return sb.String()
+}
@@ -1,6 +1,7 @@
 package game
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"time"
@@ -19,6 +20,33 @@ type Game struct {
 	whiteKings int
 	state      GameState
 	boardQueue []Board
+	positions  map[uint64]int
+	draws      drawCounter
+
+	startBoard  Board
+	startPlayer bool
+	history     []historyEntry
+	redo        []Move
+
+	whiteEngine Engine
+	redEngine   Engine
+	tablebase   *Tablebase
+}
+
+//historyEntry is a played move together with the game state before it
+type historyEntry struct {
+	move   Move
+	before gameSnapshot
+}
+
+//gameSnapshot holds everything needed to restore a game to an earlier point
+type gameSnapshot struct {
+	board   Board
+	player  bool
+	turn    int
+	state   GameState
+	running bool
+	draws   drawCounter
 }
 
 //Start starts the game
@@ -37,18 +65,44 @@ func (g *Game) IsRunning() bool {
 
 //SetupGame creates a new game instance ready to start
 func SetupGame() *Game {
-	b := boardSetup(make(Board, height*width))
+	//white goes first
+	return SetupGameFromPosition(boardSetup(make(Board, height*width)), true)
+}
+
+//SetupGameFromPosition creates a new game instance starting from the given board
+//with the given player to move (true = white)
+func SetupGameFromPosition(b Board, player bool) *Game {
+	b = b.copy()
 	w, r, wk, rk := b.getCounts()
-	return &Game{
+	g := &Game{
 		turn:       0,
 		redCount:   r,
 		whiteCount: w,
 		redKings:   rk,
 		whiteKings: wk,
-		player:     true, //white goes first
+		player:     player,
 		board:      b,
 		state:      GameStateRunning,
+		positions:  make(map[uint64]int),
+
+		startBoard:  b.copy(),
+		startPlayer: player,
+		history:     make([]historyEntry, 0),
+		redo:        make([]Move, 0),
+
+		whiteEngine: NewMinimaxEngine(),
+		redEngine:   NewMinimaxEngine(),
 	}
+	g.recordPosition()
+	return g
+}
+
+//recordPosition adds the current position to the position history
+//and returns how often it has occurred so far
+func (g *Game) recordPosition() int {
+	k := g.board.hash(g.player)
+	g.positions[k]++
+	return g.positions[k]
 }
 
 //refreshCount updates the current board counts
@@ -60,13 +114,23 @@ func (g *Game) refreshCount() {
 	g.whiteKings = wk
 }
 
+//FEN returns the current position in the draughts FEN format
+func (g *Game) FEN() string {
+	return FEN(g.board, g.player)
+}
+
 //CurrentBoard returns the current game board
 func (g *Game) CurrentBoard() Board {
 	return g.board
 }
 
 func (g *Game) CurrentEvaulation() int {
-	return g.board.evaluate()
+	return g.CurrentEvaluationDetails().Score
+}
+
+//CurrentEvaluationDetails returns the evaluation of the current board with every term
+func (g *Game) CurrentEvaluationDetails() Evaluation {
+	return EvaluateDetailed(g.board)
 }
 
 //GameState is the state the game is currently in
@@ -181,7 +245,7 @@ func mapPossibleMove(m []Move) []PossibleMove {
 			Coordinates: make([]Coordinate, 0),
 		}
 		pathFromMove(v, &path)
-		pos = append(pos, PossibleMove{v, path})
+		pos = append(pos, PossibleMove{v, path, v.CaptureCount()})
 	}
 
 	return pos
@@ -190,6 +254,9 @@ func mapPossibleMove(m []Move) []PossibleMove {
 type PossibleMove struct {
 	Move Move
 	Path Path
+	//Captures is the number of pieces the move captures,
+	//captures are forced and only the moves capturing the most pieces are possible
+	Captures int
 }
 
 //GetPossibleMoves returns the possible moves for that given field
@@ -225,27 +292,130 @@ func (g *Game) unrollMove(m *Move, maxDepth int) {
 	}
 	g.boardQueue = append(g.boardQueue, g.board.copy())
 	if maxDepth == m.Depth {
+		g.draws = g.draws.next(g.board, *m, g.board.must(m.To).isKing() && !k)
 		g.refreshCount()
 		g.endTurn()
 	}
 }
 
-//MakeAIMove triggers a computer move
-func (g *Game) MakeAIMove() {
-	if g.GameState() == GameStateRunning {
-		_, m := minimax(MaxDepth, g.board, g.player, AlphaStart, BetaStart, nil)
-		if m != nil {
-			g.unrollMove(m, m.Depth)
-		} else {
-			panic("well well well this should not happend - no solution found")
-		}
+//SetEngine sets the engine making the ai moves for the player (true = white)
+func (g *Game) SetEngine(player bool, e Engine) {
+	if player {
+		g.whiteEngine = e
+	} else {
+		g.redEngine = e
+	}
+}
+
+//SetTablebase sets the endgame tablebase used to end proven draws early, nil turns it off
+func (g *Game) SetTablebase(t *Tablebase) {
+	g.tablebase = t
+}
+
+//Engine returns the engine making the ai moves for the player (true = white)
+func (g *Game) Engine(player bool) Engine {
+	if player {
+		return g.whiteEngine
+	}
+	return g.redEngine
+}
+
+//MakeAIMove lets the engine of the current player make a move
+func (g *Game) MakeAIMove() error {
+	if g.GameState() != GameStateRunning {
+		return nil
+	}
+	a, err := g.prepareSearch()
+	if err != nil {
+		return err
 	}
+	m, info, err := a.run(context.Background())
+	return g.PlayAIResult(AIResult{Move: m, Info: info, Err: err, Done: true, position: a.position()})
 }
 
 func (g *Game) MakeMove(m Move) {
+	g.redo = g.redo[:0]
+	g.playMove(m)
+}
+
+//playMove applies the move and adds it to the history
+func (g *Game) playMove(m Move) {
+	log.Printf("Move: %s", m)
+	g.history = append(g.history, historyEntry{m, g.snapshot()})
 	g.unrollMove(&m, m.Depth)
 }
 
+//snapshot captures the current game state
+func (g *Game) snapshot() gameSnapshot {
+	return gameSnapshot{
+		board:   g.board.copy(),
+		player:  g.player,
+		turn:    g.turn,
+		state:   g.state,
+		running: g.running,
+		draws:   g.draws,
+	}
+}
+
+//Undo takes back the last move, returns false if there is no move to take back
+func (g *Game) Undo() bool {
+	if len(g.history) == 0 {
+		return false
+	}
+	e := g.history[len(g.history)-1]
+	g.history = g.history[:len(g.history)-1]
+	g.redo = append(g.redo, e.move)
+
+	g.board = e.before.board.copy()
+	g.player = e.before.player
+	g.turn = e.before.turn
+	g.state = e.before.state
+	g.running = e.before.running
+	g.draws = e.before.draws
+	g.refreshCount()
+	g.boardQueue = make([]Board, 0)
+
+	//the position history is rebuilt from the remaining moves
+	g.positions = make(map[uint64]int)
+	for _, h := range g.history {
+		g.positions[h.before.board.hash(h.before.player)]++
+	}
+	g.recordPosition()
+	log.Printf("Took back move: %s", e.move)
+	return true
+}
+
+//Redo plays the last move taken back again, returns false if there is no move to redo
+func (g *Game) Redo() bool {
+	if len(g.redo) == 0 || g.GameState() != GameStateRunning {
+		return false
+	}
+	m := g.redo[len(g.redo)-1]
+	g.redo = g.redo[:len(g.redo)-1]
+	g.playMove(m)
+	return true
+}
+
+//TakeBack undoes moves until its the given players turn again,
+//against the ai this takes back the ai reply and the own move
+func (g *Game) TakeBack(player bool) bool {
+	if !g.Undo() {
+		return false
+	}
+	for g.player != player && g.Undo() {
+	}
+	return true
+}
+
+//History returns the moves played so far
+func (g *Game) History() []Move {
+	m := make([]Move, len(g.history))
+	for i, h := range g.history {
+		m[i] = h.move
+	}
+	return m
+}
+
 //MakeMove applies the given move to the board
 //if true is returned the corresponding player has to make another move
 //the next move has to be a forced move
@@ -259,6 +429,18 @@ func (g *Game) endTurn() {
 	if g.GameState() == GameStateRunning {
 		g.turn++
 		g.player = !g.player
+		if g.recordPosition() >= 3 {
+			g.declareDraw("Same position occurred for the third time, draw")
+			return
+		}
+		if draw, reason := g.draws.draw(); draw {
+			g.declareDraw(reason)
+			return
+		}
+		if r, ok := g.tablebase.Probe(g.board, g.player); ok && r.Outcome == TablebaseDraw {
+			g.declareDraw("Tablebase proves the position is a draw")
+			return
+		}
 		log.Printf("Turn: %d | Current board eval: %d | Whites turn: %v", g.turn, g.board.evaluate(), g.player)
 		g.board.LogBoardHeurstics()
 	} else {
@@ -268,6 +450,14 @@ func (g *Game) endTurn() {
 	log.Print(g.StatusDisplay())
 }
 
+//declareDraw ends the game as a draw
+func (g *Game) declareDraw(reason string) {
+	log.Print(reason)
+	g.state = GameStateDraw
+	g.running = false
+	log.Print(g.StatusDisplay())
+}
+
 //Player indiciates wich players turn it is (True = White, False = Red)
 func (g *Game) Player() bool {
 	return g.player
@@ -0,0 +1,49 @@
+package game
+
+import (
+	"io"
+	"log"
+	"testing"
+)
+
+//startGame starts a game from the FEN position, the game logs are discarded until the test ends
+func startGame(t *testing.T, fen string) *Game {
+	out := log.Writer()
+	log.SetOutput(io.Discard)
+	t.Cleanup(func() { log.SetOutput(out) })
+	b, player, err := ParseFEN(fen)
+	if err != nil {
+		t.Fatal(err)
+	}
+	g := SetupGameFromPosition(b, player)
+	g.Start()
+	return g
+}
+
+//playMoves plays the moves given in numeric notation
+func playMoves(t *testing.T, g *Game, moves ...string) {
+	for _, text := range moves {
+		if g.GameState() != GameStateRunning {
+			t.Fatalf("move %s: the game is over (%s)", text, g.GameState())
+		}
+		m, err := ParseMove(g.CurrentBoard(), g.Player(), text)
+		if err != nil {
+			t.Fatalf("move %s in %s: %v", text, g.FEN(), err)
+		}
+		g.MakeMove(m)
+	}
+}
+
+func TestThreefoldRepetition(t *testing.T) {
+	g := startGame(t, "W:WK47,K49:BK2,K4")
+	//the starting position is on the board for the second time after four plies
+	playMoves(t, g, "47-42", "2-7", "42-47", "7-2", "47-42", "2-7", "42-47")
+	if g.GameState() != GameStateRunning {
+		t.Fatalf("game ended after the second repetition: %s", g.GameState())
+	}
+	playMoves(t, g, "7-2")
+	if g.GameState() != GameStateDraw {
+		t.Errorf("third repetition is %s, want a draw", g.GameState())
+	}
+
+}
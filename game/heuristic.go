@@ -47,13 +47,13 @@ func (b Board) getLeggardAndGrapeCount() (white int, red int) {
 			}
 			if southEast {
 				emptySquare++
-				if seok {
+				if !seok {
 					blockedSquares++
 				}
 			}
 			if southWest {
 				emptySquare++
-				if swok {
+				if !swok {
 					blockedSquares++
 				}
 			}
@@ -76,18 +76,18 @@ func (b Board) getLeggardAndGrapeCount() (white int, red int) {
 	return
 }
 
+//getLeftSideCount counts the pieces on the left side as seen by their player,
+//reds left side is whites right side
 func (b Board) getLeftSideCount() (white int, red int) {
 	white = 0
 	red = 0
 	for i := 0; i <= (height - 1); i++ {
 		for j := 0; j < 3; j++ {
-			idx := IndexOf(i, j)
-			if !has(b[idx], Empty) {
-				if has(b[idx], Player) {
-					white++
-				} else {
-					red++
-				}
+			if idx := IndexOf(i, j); !has(b[idx], Empty) && has(b[idx], Player) {
+				white++
+			}
+			if idx := IndexOf(i, width-1-j); !has(b[idx], Empty) && !has(b[idx], Player) {
+				red++
 			}
 		}
 	}
@@ -112,18 +112,17 @@ func (b Board) getMiddleCount() (white int, red int) {
 	return
 }
 
+//getRightSideCount counts the pieces on the right side as seen by their player
 func (b Board) getRightSideCount() (white int, red int) {
 	white = 0
 	red = 0
 	for i := 0; i <= (height - 1); i++ {
-		for j := width - 3; j < width; j++ {
-			idx := IndexOf(i, j)
-			if !has(b[idx], Empty) {
-				if has(b[idx], Player) {
-					white++
-				} else {
-					red++
-				}
+		for j := 0; j < 3; j++ {
+			if idx := IndexOf(i, width-1-j); !has(b[idx], Empty) && has(b[idx], Player) {
+				white++
+			}
+			if idx := IndexOf(i, j); !has(b[idx], Empty) && !has(b[idx], Player) {
+				red++
 			}
 		}
 	}
@@ -413,7 +412,7 @@ func (b Board) getVulnerablePiecesCount() (white int, red int) {
 	}
 	rskipps := b.getAllPossibleSkips(false)
 	for _, s := range rskipps {
-		white += white + (1 + s.Depth)
+		white += (1 + s.Depth)
 	}
 	return
 }
@@ -426,7 +425,7 @@ func (b Board) getSuicidalPiecesCount() (white int, red int) {
 	for _, s := range whiteMoves {
 		tmp := b.copy()
 		unrollMove(&tmp, s, true, s.Depth)
-		enemy := b.getAllPossibleSkips(false)
+		enemy := tmp.getAllPossibleSkips(false)
 		for _, s := range enemy {
 			white += (1 + s.Depth)
 		}
@@ -434,8 +433,8 @@ func (b Board) getSuicidalPiecesCount() (white int, red int) {
 	rskipps := b.getPossibleValidMovesForPlayer(false)
 	for _, s := range rskipps {
 		tmp := b.copy()
-		unrollMove(&tmp, s, true, s.Depth)
-		enemy := b.getAllPossibleSkips(true)
+		unrollMove(&tmp, s, false, s.Depth)
+		enemy := tmp.getAllPossibleSkips(true)
 		for _, s := range enemy {
 			red += (1 + s.Depth)
 		}
@@ -573,7 +572,7 @@ func heuristicSavingMove(b Board, m Move, player bool) bool {
 	for _, v := range skips {
 		td := v.allTakedowns()
 		for _, t := range td {
-			if m.From.Row == t.Row && m.From.Col == t.Col {
+			if from := m.origin(); from.Row == t.Row && from.Col == t.Col {
 				return true
 			}
 		}
@@ -638,7 +637,7 @@ func heuristicLooseProtectingMove(b Board, m Move, player bool) bool {
 	for _, v := range skips {
 		td := v.pathway()
 		for _, t := range td {
-			if m.From.Row == t.Row && m.From.Col == t.Col {
+			if from := m.origin(); from.Row == t.Row && from.Col == t.Col {
 				return true
 			}
 		}
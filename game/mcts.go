@@ -0,0 +1,289 @@
+package game
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+//RolloutPolicy decides how the moves of a simulated game are chosen
+type RolloutPolicy int
+
+const (
+	//RolloutRandom plays uniformly random moves
+	RolloutRandom RolloutPolicy = iota
+	//RolloutHeuristic prefers moves the move heuristics like and avoids moves losing a piece
+	RolloutHeuristic
+)
+
+//ParseRolloutPolicy returns the policy with the given name (random, heuristic)
+func ParseRolloutPolicy(name string) (RolloutPolicy, error) {
+	switch name {
+	case "random":
+		return RolloutRandom, nil
+	case "heuristic":
+		return RolloutHeuristic, nil
+	}
+	return RolloutRandom, fmt.Errorf("unknown rollout policy %q (random, heuristic)", name)
+}
+
+//defaultMCTSIterations is the number of iterations without any other limit
+const defaultMCTSIterations = 10000
+
+//defaultRolloutPlies ends a simulated game as a draw
+const defaultRolloutPlies = 200
+
+//mctsProgressInterval is the number of iterations between progress reports
+const mctsProgressInterval = 1000
+
+//MCTSEngine searches with Monte Carlo tree search (UCT). Every iteration walks down the tree along the most
+//promising moves, adds one new position and plays a simulated game from there to score it.
+//It needs no evaluation, the move that was explored the most is played.
+type MCTSEngine struct {
+	//Iterations is the number of simulated games per move, 0 means 10000 unless a time limit is set
+	Iterations int
+	//TimeLimit is the thinking time per move, 0 means no limit
+	TimeLimit time.Duration
+	//Exploration is the UCT exploration constant, 0 means sqrt(2)
+	Exploration float64
+	//Rollout is the policy of the simulated games
+	Rollout RolloutPolicy
+	//RolloutPlies ends a simulated game as a draw, 0 means 200
+	RolloutPlies int
+	//Seed seeds the random moves, the same seed always gives the same moves without a time limit
+	Seed int64
+
+	mu  sync.Mutex
+	rng *rand.Rand
+}
+
+//NewMCTSEngine creates a monte carlo engine with random rollouts
+func NewMCTSEngine(seed int64) *MCTSEngine {
+	return &MCTSEngine{Seed: seed}
+}
+
+//mctsNode is a position in the search tree
+type mctsNode struct {
+	move   Move
+	board  Board
+	player bool
+	draws  drawCounter
+	parent *mctsNode
+
+	children []*mctsNode
+	untried  []Move
+	//visits counts the simulations through the node, wins the results for the player that moved into it
+	visits int
+	wins   float64
+}
+
+//newMCTSNode creates the node of the position reached by the move
+func newMCTSNode(parent *mctsNode, m Move, b Board, player bool, draws drawCounter) *mctsNode {
+	n := &mctsNode{move: m, board: b, player: player, draws: draws, parent: parent}
+	if draw, _ := draws.draw(); !draw {
+		n.untried = b.getPossibleValidMovesForPlayer(player)
+	}
+	return n
+}
+
+//BestMove searches the best move for the player
+func (e *MCTSEngine) BestMove(ctx context.Context, board Board, player bool) (Move, SearchInfo, error) {
+	return e.bestMove(ctx, board, player, drawCounter{})
+}
+
+func (e *MCTSEngine) bestMove(ctx context.Context, board Board, player bool, draws drawCounter) (Move, SearchInfo, error) {
+	if err := ctx.Err(); err != nil {
+		return Move{}, SearchInfo{}, err
+	}
+	start := time.Now()
+	moves := board.getPossibleValidMovesForPlayer(player)
+	if len(moves) == 0 {
+		return Move{}, SearchInfo{}, ErrNoMove
+	}
+	//a forced move needs no search
+	if len(moves) == 1 {
+		info := SearchInfo{Move: moves[0], PV: []Move{moves[0]}}
+		info.timed(start)
+		return moves[0], info, nil
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.rng == nil {
+		e.rng = rand.New(rand.NewSource(e.Seed))
+	}
+	iterations := e.Iterations
+	if iterations <= 0 && e.TimeLimit <= 0 {
+		iterations = defaultMCTSIterations
+	}
+	var deadline time.Time
+	if e.TimeLimit > 0 {
+		deadline = start.Add(e.TimeLimit)
+	}
+
+	root := newMCTSNode(nil, Move{}, board.copy(), player, draws)
+	info := SearchInfo{}
+	for i := 1; iterations <= 0 || i <= iterations; i++ {
+		n := e.selectNode(root)
+		n.backpropagate(e.simulate(n))
+		info.Nodes++
+		if i%mctsProgressInterval == 0 {
+			e.fillInfo(&info, root, start)
+			reportProgress(ctx, info)
+		}
+		//the first iteration always finishes so there is a move to play
+		if ctx.Err() != nil || (!deadline.IsZero() && time.Now().After(deadline)) {
+			break
+		}
+	}
+	e.fillInfo(&info, root, start)
+	if err := ctx.Err(); err != nil {
+		return Move{}, info, err
+	}
+	return info.Move, info, nil
+}
+
+//selectNode walks down the tree choosing the child with the best upper confidence bound
+//until it reaches a node with untried moves, which gets expanded by one child
+func (e *MCTSEngine) selectNode(n *mctsNode) *mctsNode {
+	c := e.Exploration
+	if c <= 0 {
+		c = math.Sqrt2
+	}
+	for len(n.untried) == 0 && len(n.children) > 0 {
+		best, bestValue := n.children[0], math.Inf(-1)
+		logVisits := math.Log(float64(n.visits))
+		for _, child := range n.children {
+			value := child.wins/float64(child.visits) + c*math.Sqrt(logVisits/float64(child.visits))
+			if value > bestValue {
+				best, bestValue = child, value
+			}
+		}
+		n = best
+	}
+	if len(n.untried) == 0 {
+		return n
+	}
+	i := e.rng.Intn(len(n.untried))
+	m := n.untried[i]
+	n.untried[i] = n.untried[len(n.untried)-1]
+	n.untried = n.untried[:len(n.untried)-1]
+	next := n.board.copy()
+	unrollMove(&next, m, n.player, m.Depth)
+	child := newMCTSNode(n, m, next, !n.player, n.draws.next(next, m, n.board.must(m.origin()).isKing()))
+	n.children = append(n.children, child)
+	return child
+}
+
+//simulate plays the game from the node to its end and returns the result for white, 1 win, 0.5 draw, 0 loss
+func (e *MCTSEngine) simulate(n *mctsNode) float64 {
+	b, player, draws := n.board.copy(), n.player, n.draws
+	plies := e.RolloutPlies
+	if plies <= 0 {
+		plies = defaultRolloutPlies
+	}
+	for i := 0; i < plies; i++ {
+		if draw, _ := draws.draw(); draw {
+			return 0.5
+		}
+		moves := b.getPossibleValidMovesForPlayer(player)
+		if len(moves) == 0 {
+			if player {
+				return 0
+			}
+			return 1
+		}
+		m := e.rolloutMove(b, moves, player)
+		king := b.must(m.origin()).isKing()
+		unrollMove(&b, m, player, m.Depth)
+		draws = draws.next(b, m, king)
+		player = !player
+	}
+	return 0.5
+}
+
+//rolloutMove picks the move of a simulated game according to the rollout policy
+func (e *MCTSEngine) rolloutMove(b Board, moves []Move, player bool) Move {
+	if e.Rollout != RolloutHeuristic || len(moves) == 1 {
+		return moves[e.rng.Intn(len(moves))]
+	}
+	//the heuristics weight the moves, a move is chosen with a probability proportional to its weight
+	weights := make([]float64, len(moves))
+	total := 0.0
+	for i, m := range moves {
+		w := 1.0
+		if heuristicMoveLeadsToWin(b, m, player) {
+			w += 100
+		}
+		if heuristicSavingMove(b, m, player) {
+			w += 4
+		}
+		if heuristicMoveLeadsToKing(b, m, player) {
+			w += 4
+		}
+		if heuristicProtectingMove(b, m, player) {
+			w += 2
+		}
+		if heuristicGetsTaken(b, m, player) {
+			w /= 4
+		}
+		weights[i] = w
+		total += w
+	}
+	r := e.rng.Float64() * total
+	for i, w := range weights {
+		if r < w {
+			return moves[i]
+		}
+		r -= w
+	}
+	return moves[len(moves)-1]
+}
+
+//backpropagate adds the result of a simulation to the node and all its ancestors
+func (n *mctsNode) backpropagate(result float64) {
+	for ; n != nil; n = n.parent {
+		n.visits++
+		//wins are counted for the player that made the move into the node
+		if n.player {
+			n.wins += 1 - result
+		} else {
+			n.wins += result
+		}
+	}
+}
+
+//mostVisited returns the child explored the most, nil for a leaf
+func (n *mctsNode) mostVisited() *mctsNode {
+	var best *mctsNode
+	for _, child := range n.children {
+		if best == nil || child.visits > best.visits {
+			best = child
+		}
+	}
+	return best
+}
+
+//fillInfo sets the search info from the tree, the principal variation follows the most visited moves
+//and the score maps the win rate of the best move to -1000 (red wins) to 1000 (white wins)
+func (e *MCTSEngine) fillInfo(info *SearchInfo, root *mctsNode, start time.Time) {
+	//progress reports keep their own line, the slice is not reused
+	info.PV = make([]Move, 0)
+	best := root.mostVisited()
+	for n := best; n != nil; n = n.mostVisited() {
+		info.PV = append(info.PV, n.move)
+	}
+	info.Depth = len(info.PV)
+	if best != nil {
+		info.Move = best.move
+		rate := best.wins / float64(best.visits)
+		if !root.player {
+			rate = 1 - rate
+		}
+		info.Score = int(math.Round((rate - 0.5) * 2000))
+	}
+	info.timed(start)
+}
@@ -0,0 +1,100 @@
+package game
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+//endsGame reports if the opponent has no move left after the move
+func endsGame(b Board, m Move, player bool) bool {
+	next := b.copy()
+	unrollMove(&next, m, player, m.Depth)
+	return len(next.getPossibleValidMovesForPlayer(!player)) == 0
+}
+
+func TestMCTSPlaysOnlyMove(t *testing.T) {
+	b, player, err := ParseFEN("W:W32:B27")
+	if err != nil {
+		t.Fatal(err)
+	}
+	m, info, err := NewMCTSEngine(1).BestMove(context.Background(), b, player)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.String() != "32x21" || info.Nodes != 0 {
+		t.Errorf("played %s after %d iterations, want 32x21 without searching", m, info.Nodes)
+	}
+}
+
+func TestMCTSTakesWinningCapture(t *testing.T) {
+	//only the king landing on 50 after 5x28 leaves red without a move
+	white, _, err := ParseFEN("W:WK5,K25,K36:B23,K33,45")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, rollout := range []RolloutPolicy{RolloutRandom, RolloutHeuristic} {
+		//the mirrored board checks the results are counted for red as well
+		for _, player := range []bool{true, false} {
+			b := white
+			if !player {
+				b = white.Mirror()
+			}
+			e := &MCTSEngine{Iterations: 500, Rollout: rollout, Seed: 1}
+			m, info, err := e.BestMove(context.Background(), b, player)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !endsGame(b, m, player) {
+				t.Errorf("%s: played %s, want the capture ending the game", FEN(b, player), m)
+			}
+			if (player && info.Score <= 0) || (!player && info.Score >= 0) {
+				t.Errorf("%s: the winning side scores %d", FEN(b, player), info.Score)
+			}
+		}
+	}
+}
+
+func TestMCTSIterationLimit(t *testing.T) {
+	b := boardSetup(make(Board, height*width))
+	_, info, err := (&MCTSEngine{Iterations: 300, Seed: 1}).BestMove(context.Background(), b, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Nodes != 300 {
+		t.Errorf("ran %d iterations, want 300", info.Nodes)
+	}
+}
+
+func TestMCTSCancellation(t *testing.T) {
+	b := boardSetup(make(Board, height*width))
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, _, err := NewMCTSEngine(1).BestMove(ctx, b, true); !errors.Is(err, context.Canceled) {
+		t.Errorf("cancelled search returned %v", err)
+	}
+
+	//a search far longer than the timeout stops when the context ends
+	ctx, cancel = context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	_, _, err := (&MCTSEngine{Iterations: 1 << 30, Seed: 1}).BestMove(ctx, b, true)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("search past its deadline returned %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("search stopped %s after being cancelled", elapsed)
+	}
+}
+
+func TestMCTSNoMove(t *testing.T) {
+	//the white man is blocked and can not capture
+	b, player, err := ParseFEN("W:W46:B41,37")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := NewMCTSEngine(1).BestMove(context.Background(), b, player); !errors.Is(err, ErrNoMove) {
+		t.Errorf("got %v, want ErrNoMove", err)
+	}
+}
@@ -1,236 +1,538 @@
 package game
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"math"
+	"math/rand"
 	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
 )
 
+//MaxDepth is the default search depth of the minimax engine
 const MaxDepth = 4
 const AlphaStart = math.MinInt
 const BetaStart = math.MaxInt
 
 const pieceBaseVaue = 1
 
+//winScore is the score of a won board, a lost board scores -winScore so the scores of both sides mirror.
+//The search takes off the plies to the end of the game, like the tablebase does.
+const winScore = math.MaxInt32
+
+//evaluate scores the board with the default weights
 func (b Board) evaluate() int {
-	w, r, wk, rk := b.getCounts()
+	return b.evaluateWith(&DefaultWeights)
+}
 
+//evaluateWith scores the board from whites perspective, terms with weight 0 are skipped
+func (b Board) evaluateWith(wt *Weights) int {
+	return b.evaluateTerms(wt, false).Score
+}
+
+//terminalScore returns the score of a board where a side has lost all pieces or can not move any of them.
+//If both sides are stuck the player to move loses, which the board alone does not tell, so it is not decided then.
+func (b Board) terminalScore() (int, bool) {
+	w, r, _, _ := b.getCounts()
 	if w == 0 {
-		return math.MinInt32
+		return -winScore, true
 	}
 	if r == 0 {
-		return math.MaxInt32
+		return winScore, true
 	}
-
-	base := (w * pieceBaseVaue * 2) - (r * pieceBaseVaue * 2)
-	base = base + (wk*pieceBaseVaue*15 - rk*pieceBaseVaue*15)
-
-	wbr, rbr := b.getGoldenStoneCount()
-	base = base + (wbr*3 - rbr*3)
-
-	wmb, rmb := b.getMiddleBoxCount()
-	base = base + (wmb*3 - rmb*3)
-	wms, rms := b.getMiddleCount()
-	base = base + (wms*2 - rms*2)
-	wls, rls := b.getLeftSideCount()
-	base = base + (wls*1 - rls*1)
-	wrs, rrs := b.getRightSideCount()
-	base = base + (wrs*1 - rrs*1)
-	wpr, rpr := b.getProtectionCount()
-	base = base + (wpr*4 - rpr*4)
-
 	wst, rst, _, _ := b.getStuckPiecesCount()
+	if wst == w && rst == r {
+		return 0, false
+	}
 	if wst == w {
-		return math.MinInt32
+		return -winScore, true
 	}
 	if rst == r {
-		return math.MaxInt32
+		return winScore, true
 	}
+	return 0, false
+}
 
-	// base = base + (wst * -1) - (rst * -1) + (wstk*-2 - rstk*-2)
-
-	// wlgr, rlgr := b.getLeggardAndGrapeCount()
-	// base = base + (wlgr * -1 * pieceBaseVaue) - (rlgr * -1 * pieceBaseVaue)
-
-	wfs, rfs := b.getFullSquares()
-	base = base + (wfs*5 - rfs*5)
+//decidedScore reports if the score is a certain win or loss, either by the board or the tablebase
+func decidedScore(score int) bool {
+	const plies = maxTablebasePlies + maxSearchDepth + maxQuiescenceDepth
+	return score >= winScore-plies || score <= -winScore+plies
+}
 
-	whs, rhs := b.getHalfSquare()
-	base = base + (whs*3 - rhs*3)
+//distanceScore moves a win or loss ply plies away towards 0, so quicker wins and slower losses score higher.
+//Other scores are returned unchanged.
+func distanceScore(score int, ply int) int {
+	if !decidedScore(score) {
+		return score
+	}
+	if score > 0 {
+		return score - ply
+	}
+	return score + ply
+}
 
-	wgs, rgs := b.getFullGates()
-	base = base + (wgs*2 - rgs*2)
+//lossScore is the score of the player to move having no move left
+func lossScore(player bool) int {
+	if player {
+		return -winScore
+	}
+	return winScore
+}
 
-	whgs, rhgs := b.getHalfGates()
-	base = base + (whgs - rhgs)
+//LogBoardHeurstics logs the counts and the weighted score of every evaluation term
+func (b Board) LogBoardHeurstics() {
+	e := EvaluateDetailed(b)
+	if e.Decided {
+		log.Printf("Decided board | %s", e)
+		return
+	}
+	var whiteStats strings.Builder
+	var redStats strings.Builder
+	var scores strings.Builder
+	for _, t := range e.Terms {
+		fmt.Fprintf(&whiteStats, " %s: %02d |", t.Name, t.White)
+		fmt.Fprintf(&redStats, " %s: %02d |", t.Name, t.Red)
+		fmt.Fprintf(&scores, " %s: %d |", t.Name, t.Score)
+	}
+	log.Printf("White| %s", whiteStats.String())
+	log.Printf("Red  | %s", redStats.String())
+	log.Printf("Score| %s total: %d", scores.String(), e.Score)
+}
 
-	wps, rps := b.getPincers()
-	base = base + (wps*2 - rps*2)
+func maxOf(i, j int) int {
+	if i > j {
+		return i
+	}
+	return j
+}
 
-	llw, lrw := b.getLargestConnectedField()
-	base = base + (llw - lrw)
+func minOf(i, j int) int {
+	if i < j {
+		return i
+	}
+	return j
+}
 
-	// wvp, rvp := b.getVulnerablePiecesCount()
-	// base = base + (wvp * -5) - (rvp * -5)
+//maxQuiescenceDepth is the safety cap of the capture plies searched beyond the search depth
+const maxQuiescenceDepth = 8
+
+//maxSearchDepth bounds the iterative deepening when only a time or node limit is set
+const maxSearchDepth = 64
+
+//MinimaxEngine searches the best move with alpha beta pruned minimax and the board heuristics.
+//The search deepens iteratively one ply at a time until the depth, time or node limit is reached
+//and plays the best move of the last finished iteration.
+//Without a time limit the engine is deterministic, the same position always gives the same move:
+//every search starts with an empty transposition table.
+type MinimaxEngine struct {
+	//Depth is the maximum search depth in plies,
+	//0 means no depth limit if a time or node limit is set
+	Depth int
+	//TimeLimit is the thinking time per move, 0 means no limit
+	TimeLimit time.Duration
+	//NodeLimit is the maximum number of searched nodes per move, 0 means no limit
+	NodeLimit uint64
+	//TableSize is the number of transposition table entries, 0 means the default size
+	TableSize int
+	//Seed makes the engine choose randomly among equally scored best moves,
+	//the same seed and moves always give the same choices. 0 keeps the engine deterministic.
+	Seed int64
+	//Workers is the number of goroutines splitting the moves at the root, 0 or 1 searches serially
+	Workers int
+	//Weights are the evaluation weights, nil means DefaultWeights
+	Weights *Weights
+	//Tablebase gives the exact scores of positions with few pieces, nil searches without
+	Tablebase *Tablebase
+
+	//mu makes a search wait for the previous one, e.g. a cancelled search still running in the background
+	mu    sync.Mutex
+	table *transpositionTable
+	rng   *rand.Rand
+}
 
-	// wsc, rsc := b.getSuicidalPiecesCount()
-	// base = base + (wsc * -20) - (rsc * -20)
+//NewMinimaxEngine creates a minimax engine with the default search depth
+func NewMinimaxEngine() *MinimaxEngine {
+	return &MinimaxEngine{Depth: MaxDepth}
+}
 
-	whiteMoveWeight := 0
-	redMoveWeight := 0
+//BestMove searches the best move for the player
+func (e *MinimaxEngine) BestMove(ctx context.Context, board Board, player bool) (Move, SearchInfo, error) {
+	return e.bestMove(ctx, board, player, drawCounter{})
+}
 
-	players := []bool{true, false}
-	for _, p := range players {
-		moves := b.getPossibleValidMovesForPlayer(p)
-		for _, w := range moves {
-			moveWeight := 0
-			//the move saves a check from beeing taken
-			if heuristicSavingMove(b, w, true) {
-				moveWeight += pieceBaseVaue * 14
-			}
-			if heuristicProtectingMove(b, w, true) {
-				moveWeight += pieceBaseVaue * 16
-			}
-			if heuristicMoveLeadsToKing(b, w, true) {
-				moveWeight += pieceBaseVaue * 20
-			}
-			if heuristicMoveLeadsToWin(b, w, true) {
-				moveWeight += 1000
-			}
-			if w.Depth > 0 {
-				moveWeight += (50 * pieceBaseVaue) + (w.Depth + pieceBaseVaue)
-			}
-			if heuristicGetsTaken(b, w, true) {
-				moveWeight += (-99 * pieceBaseVaue)
-			}
-			if heuristicLooseProtectingMove(b, w, true) {
-				moveWeight += (-15 * pieceBaseVaue)
-			}
-			if p {
-				whiteMoveWeight = maxOf(whiteMoveWeight, moveWeight)
-			} else {
-				redMoveWeight = maxOf(redMoveWeight, moveWeight)
-			}
+func (e *MinimaxEngine) bestMove(ctx context.Context, board Board, player bool, draws drawCounter) (Move, SearchInfo, error) {
+	if err := ctx.Err(); err != nil {
+		return Move{}, SearchInfo{}, err
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	start := time.Now()
+	moves := board.getPossibleValidMovesForPlayer(player)
+	if len(moves) == 0 {
+		return Move{}, SearchInfo{}, ErrNoMove
+	}
+	//a forced move needs no search
+	if len(moves) == 1 {
+		info := SearchInfo{Move: moves[0], PV: []Move{moves[0]}, Depth: 0, Score: board.evaluateWith(e.weights())}
+		info.timed(start)
+		return moves[0], info, nil
+	}
+	return e.deepen(ctx, board, player, draws, moves, start)
+}
 
+//deepen runs the iterative deepening search over the moves of the position, even a single move is searched.
+//The caller holds mu.
+func (e *MinimaxEngine) deepen(ctx context.Context, board Board, player bool, draws drawCounter, moves []Move, start time.Time) (Move, SearchInfo, error) {
+	maxDepth := e.Depth
+	if maxDepth <= 0 {
+		maxDepth = MaxDepth
+		if e.TimeLimit > 0 || e.NodeLimit > 0 {
+			maxDepth = maxSearchDepth
+		}
+	}
+	if e.table == nil {
+		size := e.TableSize
+		if size <= 0 {
+			size = defaultTableSize
 		}
+		e.table = newTranspositionTable(size)
+	}
+	e.table.newGeneration()
+	if e.Seed != 0 && e.rng == nil {
+		e.rng = rand.New(rand.NewSource(e.Seed))
+	}
+	s := &search{ctx: ctx, nodeLimit: e.NodeLimit, sharedNodes: new(uint64), table: e.table, rng: e.rng, weights: e.weights(), tablebase: e.Tablebase}
+	if e.TimeLimit > 0 {
+		s.deadline = time.Now().Add(e.TimeLimit)
 	}
-	base += ((whiteMoveWeight * 2) - (redMoveWeight * 2))
 
-	return base
+	best := moves[0]
+	info := SearchInfo{}
+	ties := make([]Move, 0)
+	for depth := 1; depth <= maxDepth; depth++ {
+		s.iteration = depth
+		s.rootTies = nil
+		s.interruptible = depth > 1
+		var score int
+		var m *Move
+		if e.Workers > 1 {
+			score, m = s.parallelRoot(e.Workers, depth, board, player, draws)
+		} else {
+			score, m = s.minimax(depth, board, player, AlphaStart, BetaStart, draws)
+		}
+		//the result of an aborted iteration is incomplete and gets dropped
+		if s.aborted {
+			break
+		}
+		if m != nil {
+			best = *m
+		}
+		ties = s.rootTies
+		info.Move = best
+		info.PV = s.linePV(0)
+		info.Depth = depth
+		info.Score = score
+		info.Nodes = s.nodes
+		info.timed(start)
+		reportProgress(ctx, info)
+		//no need to search deeper once a win or loss is certain,
+		//limits already reached after the first iteration end the search as well
+		if decidedScore(score) || s.limitReached() {
+			break
+		}
+	}
+	if s.rng != nil && len(ties) > 1 {
+		best = ties[s.rng.Intn(len(ties))]
+		//the line was searched for another of the tied moves
+		if len(info.PV) > 0 && info.PV[0].identity() != best.identity() {
+			info.PV = []Move{best}
+		}
+	}
+	info.Move = best
+	info.Nodes = s.nodes
+	info.QuiescenceNodes = s.quiescenceNodes
+	info.TableHits = s.tableHits
+	info.TablebaseHits = s.tablebaseHits
+	info.Cutoffs = s.cutoffs
+	info.FirstMoveCutoffs = s.firstMoveCutoffs
+	info.timed(start)
+	if err := ctx.Err(); err != nil {
+		return Move{}, info, err
+	}
+	return best, info, nil
 }
 
-type HeuristicStat struct {
-	name  string
-	white int
-	red   int
+//weights returns the evaluation weights of the engine
+func (e *MinimaxEngine) weights() *Weights {
+	if e.Weights == nil {
+		return &DefaultWeights
+	}
+	return e.Weights
 }
 
-func (b Board) LogBoardHeurstics() {
-	stats := make([]HeuristicStat, 0)
+//search holds the limits and counters of a single search
+type search struct {
+	ctx       context.Context
+	deadline  time.Time
+	nodeLimit uint64
+	nodes     uint64
+	//sharedNodes counts the nodes of all workers of a parallel search for the node limit
+	sharedNodes *uint64
+	aborted     bool
+	table       *transpositionTable
+	weights     *Weights
+	tablebase   *Tablebase
+	//rng chooses among equally scored root moves, nil for a deterministic search
+	rng *rand.Rand
+	//rootTies are the root moves sharing the best score
+	rootTies []Move
+	//iteration is the depth of the current iteration
+	iteration int
+	//killers are the last two quiet moves per ply that caused a cutoff
+	killers [maxSearchDepth + 1][2]moveIdentity
+	//history scores quiet moves by from and to square whenever they cause a cutoff
+	history [100][100]int
+	//pv is the triangular table of the principal variation, pv[ply] holds the best line from ply on
+	pv       [maxSearchDepth + 1][maxSearchDepth + 1]Move
+	pvLength [maxSearchDepth + 1]int
+
+	quiescenceNodes  uint64
+	tableHits        uint64
+	tablebaseHits    uint64
+	cutoffs          uint64
+	firstMoveCutoffs uint64
+	//interruptible is false while the first iteration runs, its always finished
+	interruptible bool
+}
 
-	wbr, rbr := b.getGoldenStoneCount()
-	stats = append(stats, HeuristicStat{"g.stones", wbr, rbr})
+//stop checks if the search has to be aborted,
+//compared to the evaluation looking at the clock on every node is cheap
+func (s *search) stop() bool {
+	if !s.aborted && s.limitReached() {
+		s.aborted = true
+	}
+	return s.aborted
+}
 
-	// wlgr, rlgr := b.getLeggardAndGrapeCount()
-	// stats = append(stats, HeuristicStat{"l&g", wlgr, rlgr})
+//limitReached checks if the search ran out of time or nodes or got cancelled
+func (s *search) limitReached() bool {
+	return (s.nodeLimit > 0 && atomic.LoadUint64(s.sharedNodes) >= s.nodeLimit) ||
+		(!s.deadline.IsZero() && time.Now().After(s.deadline)) ||
+		s.ctx.Err() != nil
+}
 
-	wmb, rmb := b.getMiddleBoxCount()
-	stats = append(stats, HeuristicStat{"m.box", wmb, rmb})
+//probeTablebase returns the exact score of the position if the tablebase holds it.
+//The tablebase does not know the draw rules, a win or loss taking more plies than they leave is a draw.
+func (s *search) probeTablebase(board Board, player bool, draws drawCounter, ply int) (int, bool) {
+	r, ok := s.tablebase.Probe(board, player)
+	if !ok {
+		return 0, false
+	}
+	s.tablebaseHits++
+	if r.Plies > draws.remaining(board) {
+		return 0, true
+	}
+	return distanceScore(r.score(player), ply), true
+}
 
-	wms, rms := b.getMiddleCount()
-	stats = append(stats, HeuristicStat{"m.", wms, rms})
+//visit counts a searched node
+func (s *search) visit() {
+	s.nodes++
+	atomic.AddUint64(s.sharedNodes, 1)
+}
 
-	wls, rls := b.getLeftSideCount()
-	stats = append(stats, HeuristicStat{"l.", wls, rls})
+//updatePV makes the move followed by the line of the next ply the best line of the ply
+func (s *search) updatePV(ply int, m Move) {
+	s.pv[ply][ply] = m
+	n := s.pvLength[ply+1]
+	copy(s.pv[ply][ply+1:], s.pv[ply+1][ply+1:n])
+	s.pvLength[ply] = maxOf(n, ply+1)
+}
 
-	wrs, rrs := b.getRightSideCount()
-	stats = append(stats, HeuristicStat{"r.", wrs, rrs})
+//linePV returns a copy of the best line found from the ply on
+func (s *search) linePV(ply int) []Move {
+	pv := make([]Move, s.pvLength[ply]-ply)
+	copy(pv, s.pv[ply][ply:s.pvLength[ply]])
+	return pv
+}
 
-	wpr, rpr := b.getProtectionCount()
-	stats = append(stats, HeuristicStat{"protection count", wpr, rpr})
-	wst, rst, _, _ := b.getStuckPiecesCount()
-	stats = append(stats, HeuristicStat{"stuck pieces", wst, rst})
-	wfs, rfs := b.getFullSquares()
-	stats = append(stats, HeuristicStat{"full squares", wfs, rfs})
-	whs, rhs := b.getHalfSquare()
-	stats = append(stats, HeuristicStat{"half squares", whs, rhs})
-	wgs, rgs := b.getFullGates()
-	stats = append(stats, HeuristicStat{"full gates", wgs, rgs})
-	whgs, rhgs := b.getHalfGates()
-	stats = append(stats, HeuristicStat{"half gates", whgs, rhgs})
-	wps, rps := b.getPincers()
-	stats = append(stats, HeuristicStat{"pincers", wps, rps})
-
-	llw, lrw := b.getLargestConnectedField()
-	stats = append(stats, HeuristicStat{"largest field", llw, lrw})
-
-	// wvp, rvp := b.getVulnerablePiecesCount()
-	// stats = append(stats, HeuristicStat{"vulnerable pieces", wvp, rvp})
-
-	// wsc, rsc := b.getSuicidalPiecesCount()
-	// stats = append(stats, HeuristicStat{"suicidal pieces", wsc, rsc})
+func (s *search) minimax(depth int, board Board, player bool, alpha int, beta int, draws drawCounter) (int, *Move) {
+	s.visit()
+	ply := s.iteration - depth
+	s.pvLength[ply] = ply
+	if draw, _ := draws.draw(); draw {
+		return 0, nil
+	}
+	if !board.playable() {
+		return distanceScore(board.evaluateWith(s.weights), ply), nil
+	}
+	//the root needs a move, below it the tablebase score is exact
+	if ply > 0 {
+		if score, ok := s.probeTablebase(board, player, draws, ply); ok {
+			return score, nil
+		}
+	}
+	if depth == 0 {
+		return s.quiesce(board, player, alpha, beta, draws, 0), nil
+	}
+	if s.interruptible && s.stop() {
+		return 0, nil
+	}
 
-	var whiteStats strings.Builder
-	var redStats strings.Builder
-	for _, v := range stats {
-		fmt.Fprintf(&whiteStats, " %s: %02d |", v.name, v.white)
-		fmt.Fprintf(&redStats, " %s: %02d |", v.name, v.red)
+	key := board.searchKey(player, draws)
+	var hashMove *Move
+	if e, ok := s.table.probe(key); ok {
+		s.tableHits++
+		//the root is always searched so the moves tied for the best score are known
+		if e.depth >= depth && ply > 0 {
+			//wins and losses are stored counted from the position
+			score := distanceScore(e.score, ply)
+			switch e.bound {
+			case boundExact:
+				s.pv[ply][ply] = e.move
+				s.pvLength[ply] = ply + 1
+				return score, &e.move
+			case boundLower:
+				alpha = maxOf(alpha, score)
+			case boundUpper:
+				beta = minOf(beta, score)
+			}
+			if alpha >= beta {
+				return score, &e.move
+			}
+		}
+		hashMove = &e.move
 	}
-	log.Printf("White| %s", whiteStats.String())
-	log.Printf("Red  | %s", redStats.String())
-}
 
-func maxOf(i, j int) int {
-	if i > j {
-		return i
+	//the bound stored later is relative to the window after the table narrowed it
+	alphaStart, betaStart := alpha, beta
+	value := math.MaxInt
+	if player {
+		value = math.MinInt
+	}
+	var move *Move
+	children := possibleMoves(player, board)
+	if len(children) == 0 {
+		return distanceScore(lossScore(player), ply), nil
+	}
+	s.orderMoves(board, children, hashMove, ply)
+	for i, c := range children {
+		k, v := c.move, c.board
+		childAlpha, childBeta := alpha, beta
+		//to choose randomly among equally scored moves ties at the root have to be scored exactly
+		if ply == 0 && s.rng != nil && move != nil {
+			if player && alpha > math.MinInt {
+				childAlpha--
+			} else if !player && beta < math.MaxInt {
+				childBeta++
+			}
+		}
+		eval, _ := s.minimax(depth-1, v, !player, childAlpha, childBeta, draws.next(v, k, board.must(k.origin()).isKing()))
+		if s.aborted {
+			return 0, nil
+		}
+		improved := move == nil || (player && eval > value) || (!player && eval < value)
+		if improved {
+			value = eval
+			best := k
+			move = &best
+			s.updatePV(ply, k)
+		}
+		if ply == 0 {
+			if improved {
+				s.rootTies = []Move{k}
+			} else if eval == value {
+				s.rootTies = append(s.rootTies, k)
+			}
+		}
+		if player {
+			alpha = maxOf(alpha, eval)
+		} else {
+			beta = minOf(beta, eval)
+		}
+		if alpha >= beta {
+			s.cutoffs++
+			if i == 0 {
+				s.firstMoveCutoffs++
+			}
+			if k.Takes == nil {
+				s.addKiller(k, ply)
+				s.history[k.origin().ToIndex()][k.To.ToIndex()] += depth * depth
+			}
+			break
+		}
 	}
-	return j
-}
 
-func minOf(i, j int) int {
-	if i < j {
-		return i
+	if move != nil {
+		b := boundExact
+		if value <= alphaStart {
+			b = boundUpper
+		} else if value >= betaStart {
+			b = boundLower
+		}
+		s.table.store(key, depth, distanceScore(value, -ply), b, *move)
 	}
-	return j
+	return value, move
 }
 
-func minimax(depth int, board Board, player bool, alpha int, beta int, m *Move) (int, *Move) {
-	terminal := !board.playable()
-	if depth == 0 || terminal {
-		return board.evaluate(), m
+//quiesce keeps searching at the leaves as long as the player to move has to capture,
+//so positions are only evaluated when they are quiet. As captures are compulsory
+//the player can not decline them and there is no stand pat score while capturing.
+func (s *search) quiesce(board Board, player bool, alpha int, beta int, draws drawCounter, ply int) int {
+	if ply > 0 {
+		s.visit()
+		s.quiescenceNodes++
+		if draw, _ := draws.draw(); draw {
+			return 0
+		}
 	}
+	//the quiescence search starts at the leaves of the iteration
+	rootPly := s.iteration + ply
+	if !board.playable() || ply >= maxQuiescenceDepth {
+		return distanceScore(board.evaluateWith(s.weights), rootPly)
+	}
+	if ply > 0 {
+		if score, ok := s.probeTablebase(board, player, draws, rootPly); ok {
+			return score
+		}
+	}
+	moves := board.getPossibleValidMovesForPlayer(player)
+	if len(moves) == 0 {
+		return distanceScore(lossScore(player), rootPly)
+	}
+	if moves[0].Takes == nil {
+		return board.evaluateWith(s.weights)
+	}
+	if s.interruptible && s.stop() {
+		return 0
+	}
+
+	value := math.MaxInt
 	if player {
-		value := math.MinInt
-		var move *Move
-		for k, v := range possibleMoves(player, board) {
-			eval, _ := minimax(depth-1, v, !player, alpha, beta, &k)
+		value = math.MinInt
+	}
+	for _, m := range moves {
+		next := board.copy()
+		unrollMove(&next, m, player, m.Depth)
+		eval := s.quiesce(next, !player, alpha, beta, draws.next(next, m, board.must(m.origin()).isKing()), ply+1)
+		if s.aborted {
+			return 0
+		}
+		if player {
 			value = maxOf(value, eval)
-			if value == eval {
-				move = &k
-			}
 			alpha = maxOf(alpha, eval)
-			if eval >= beta {
-				return value, move
-			}
-		}
-		return value, move
-	} else {
-		value := math.MaxInt
-		var move *Move
-		for k, v := range possibleMoves(player, board) {
-			eval, _ := minimax(depth-1, v, !player, alpha, beta, &k)
+		} else {
 			value = minOf(value, eval)
-			if value == eval {
-				move = &k
-			}
 			beta = minOf(beta, eval)
-			if eval <= alpha {
-				return value, move
-			}
 		}
-		return value, move
+		if alpha >= beta {
+			break
+		}
 	}
+	return value
 }
 
 func unrollMove(b *Board, move Move, player bool, maxDepth int) bool {
@@ -246,13 +548,20 @@ func unrollMove(b *Board, move Move, player bool, maxDepth int) bool {
 	return king
 }
 
-func possibleMoves(player bool, board Board) map[Move]Board {
-	m := make(map[Move]Board)
+//child is a possible move together with the board it leads to
+type child struct {
+	move  Move
+	board Board
+}
+
+//possibleMoves returns the possible moves of the player with their resulting boards in generation order
+func possibleMoves(player bool, board Board) []child {
 	possible := board.getPossibleValidMovesForPlayer(player)
+	m := make([]child, 0, len(possible))
 	for _, move := range possible {
 		tmp := board.copy()
 		unrollMove(&tmp, move, player, move.Depth)
-		m[move] = tmp
+		m = append(m, child{move, tmp})
 	}
 	return m
 }
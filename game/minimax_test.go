@@ -0,0 +1,32 @@
+package game
+
+import (
+	"context"
+	"testing"
+)
+
+func TestSearchScoresWinsByDistance(t *testing.T) {
+	tests := []struct {
+		fen   string
+		plies int
+	}{
+		//the king captures the last red piece
+		{"W:WK46:B28", 1},
+		//the king blocks the man, which has to step into the capture
+		{"W:WK46:B6", 3},
+	}
+	for _, tt := range tests {
+		b, player, err := ParseFEN(tt.fen)
+		if err != nil {
+			t.Fatal(err)
+		}
+		e := &MinimaxEngine{Depth: 6}
+		_, info, err := e.BestMove(context.Background(), b, player)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := winScore - tt.plies; info.Score != want {
+			t.Errorf("%s: score %d, want a win in %d plies (%d)", tt.fen, info.Score, tt.plies, want)
+		}
+	}
+}
@@ -0,0 +1,112 @@
+package game
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+//squareCount is the number of playable (dark) squares on the board
+const squareCount = 50
+
+//CoordinateOfSquare returns the coordinate of the square with the given official number (1-50)
+func CoordinateOfSquare(n int) (bool, Coordinate) {
+	if n < 1 || n > squareCount {
+		return false, Coordinate{}
+	}
+	i := n - 1
+	r := i / (width / 2)
+	c := (i % (width / 2)) * 2
+	if r%2 == 0 {
+		c++
+	}
+	return true, Coordinate{r, c}
+}
+
+//Square returns the official number (1-50) of the coordinate, 0 if its not a playable square
+func (c Coordinate) Square() int {
+	if c.Row < 0 || c.Row >= height || c.Col < 0 || c.Col >= width || (c.Row+c.Col)%2 == 0 {
+		return 0
+	}
+	return c.Row*(width/2) + c.Col/2 + 1
+}
+
+//String returns the move in the standard numeric notation including the full capture path
+//(e.g. `32-28`, `19x28` or `26x37x48`)
+func (m Move) String() string {
+	path := m.pathway()
+	squares := make([]string, 0, len(path)+1)
+	squares = append(squares, strconv.Itoa(m.origin().Square()))
+	for _, c := range path {
+		squares = append(squares, strconv.Itoa(c.Square()))
+	}
+	if m.Takes != nil {
+		return strings.Join(squares, "x")
+	}
+	return strings.Join(squares, "-")
+}
+
+//ParseMove resolves a move in the standard numeric notation against the legal moves of the player.
+//The notation has to name the start and end square, intermediate squares are optional
+//and only needed if several captures with different results share the start and end square.
+func ParseMove(b Board, player bool, text string) (Move, error) {
+	text = strings.TrimSpace(text)
+	capture := strings.Contains(text, "x")
+	sep := "-"
+	if capture {
+		sep = "x"
+	}
+	parts := strings.Split(text, sep)
+	if len(parts) < 2 {
+		return Move{}, fmt.Errorf("invalid move %q", text)
+	}
+	squares := make([]int, len(parts))
+	for i, p := range parts {
+		n, err := parseSquare(p)
+		if err != nil {
+			return Move{}, fmt.Errorf("invalid move %q: %w", text, err)
+		}
+		squares[i] = n
+	}
+
+	var found *Move
+	for _, m := range b.getPossibleValidMovesForPlayer(player) {
+		if (m.Takes != nil) != capture || m.origin().Square() != squares[0] || m.To.Square() != squares[len(squares)-1] {
+			continue
+		}
+		if !containsSquares(m.pathway(), squares[1:]) {
+			continue
+		}
+		if found != nil && !sameResult(b, *found, m, player) {
+			return Move{}, fmt.Errorf("ambiguous move %q", text)
+		}
+		if found == nil {
+			mc := m
+			found = &mc
+		}
+	}
+	if found == nil {
+		return Move{}, fmt.Errorf("illegal move %q", text)
+	}
+	return *found, nil
+}
+
+//containsSquares checks if the squares appear in the given order on the path
+func containsSquares(path []Coordinate, squares []int) bool {
+	i := 0
+	for _, c := range path {
+		if i < len(squares) && c.Square() == squares[i] {
+			i++
+		}
+	}
+	return i == len(squares)
+}
+
+//sameResult checks if both moves lead to the same board
+func sameResult(b Board, m1, m2 Move, player bool) bool {
+	b1 := b.copy()
+	unrollMove(&b1, m1, player, m1.Depth)
+	b2 := b.copy()
+	unrollMove(&b2, m2, player, m2.Depth)
+	return b1.hash(player) == b2.hash(player)
+}
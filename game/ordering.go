@@ -0,0 +1,75 @@
+package game
+
+import "sort"
+
+//order scores of the move classes, higher scores are searched first
+const (
+	orderHashMove = 1 << 30
+	orderCapture  = 1 << 26
+	orderPromote  = 1 << 25
+	orderKiller   = 1 << 24
+)
+
+//orderMoves sorts the children so the most promising moves are searched first:
+//the best move of an earlier search, the most valuable captures and promotions,
+//the killer moves of the ply and then the quiet moves by their history score
+func (s *search) orderMoves(board Board, children []child, hashMove *Move, ply int) {
+	var hashID moveIdentity
+	if hashMove != nil {
+		hashID = hashMove.identity()
+	}
+	scores := make([]int, len(children))
+	for i, c := range children {
+		id := c.move.identity()
+		switch {
+		case hashMove != nil && id == hashID:
+			scores[i] = orderHashMove
+		case c.move.Takes != nil:
+			scores[i] = orderCapture + captureValue(board, c.move)
+		case !board.must(c.move.origin()).isKing() && c.board.must(c.move.To).isKing():
+			scores[i] = orderPromote
+		case id == s.killers[ply][0]:
+			scores[i] = orderKiller + 1
+		case id == s.killers[ply][1]:
+			scores[i] = orderKiller
+		default:
+			scores[i] = minOf(s.history[id.from][id.to], orderKiller-1)
+		}
+	}
+	sort.Stable(childOrder{children, scores})
+}
+
+//captureValue rates the pieces taken by the move, kings count more than men
+func captureValue(board Board, m Move) int {
+	v := 0
+	for _, c := range m.allTakedowns() {
+		if board.must(c).isKing() {
+			v += 3
+		} else {
+			v++
+		}
+	}
+	return v
+}
+
+//addKiller remembers a quiet move that caused a cutoff at the ply
+func (s *search) addKiller(m Move, ply int) {
+	id := m.identity()
+	if s.killers[ply][0] != id {
+		s.killers[ply][1] = s.killers[ply][0]
+		s.killers[ply][0] = id
+	}
+}
+
+//childOrder sorts children by descending score
+type childOrder struct {
+	children []child
+	scores   []int
+}
+
+func (o childOrder) Len() int           { return len(o.children) }
+func (o childOrder) Less(i, j int) bool { return o.scores[i] > o.scores[j] }
+func (o childOrder) Swap(i, j int) {
+	o.children[i], o.children[j] = o.children[j], o.children[i]
+	o.scores[i], o.scores[j] = o.scores[j], o.scores[i]
+}
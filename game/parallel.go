@@ -0,0 +1,158 @@
+package game
+
+import (
+	"math"
+	"sync"
+)
+
+//rootResult is the score of a root move searched by a worker
+type rootResult struct {
+	eval int
+	//exact is false if the move failed low, its score is then only a bound
+	exact bool
+	//pv is the line following the move
+	pv []Move
+}
+
+//parallelRoot searches the root moves on several workers sharing the transposition table.
+//The first move is searched alone to get a bound, the remaining moves are split among the workers
+//which narrow their window with the best score found so far. Only exactly scored moves can become
+//the best move and ties go to the move ordered first, so it plays as strong as the serial search.
+//The workers order their moves by their own killer and history tables though, so among equally scored
+//moves another one than in the serial search can be chosen and the move depends on the scheduling.
+func (s *search) parallelRoot(workers int, depth int, board Board, player bool, draws drawCounter) (int, *Move) {
+	s.visit()
+	s.pvLength[0] = 0
+	key := board.searchKey(player, draws)
+	var hashMove *Move
+	if e, ok := s.table.probe(key); ok {
+		s.tableHits++
+		hashMove = &e.move
+	}
+	children := possibleMoves(player, board)
+	if len(children) == 0 {
+		return s.minimax(depth, board, player, AlphaStart, BetaStart, draws)
+	}
+	s.orderMoves(board, children, hashMove, 0)
+	next := func(c child) drawCounter {
+		return draws.next(c.board, c.move, board.must(c.move.origin()).isKing())
+	}
+
+	first, _ := s.minimax(depth-1, children[0].board, !player, AlphaStart, BetaStart, next(children[0]))
+	if s.aborted {
+		return 0, nil
+	}
+
+	var mu sync.Mutex
+	bestValue := first
+	results := make([]rootResult, len(children))
+	results[0] = rootResult{first, true, s.linePV(1)}
+	jobs := make(chan int, len(children))
+	for i := 1; i < len(children); i++ {
+		jobs <- i
+	}
+	close(jobs)
+
+	forks := make([]*search, workers)
+	var wg sync.WaitGroup
+	for w := range forks {
+		f := s.fork()
+		forks[w] = f
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				mu.Lock()
+				bound := bestValue
+				mu.Unlock()
+				alpha, beta := bound, math.MaxInt
+				//with a random generator ties have to be scored exactly as well
+				if s.rng != nil {
+					alpha--
+				}
+				if !player {
+					alpha, beta = math.MinInt, bound
+					if s.rng != nil {
+						beta++
+					}
+				}
+				eval, _ := f.minimax(depth-1, children[i].board, !player, alpha, beta, next(children[i]))
+				if f.aborted {
+					return
+				}
+				exact := (player && eval > alpha) || (!player && eval < beta)
+				mu.Lock()
+				results[i] = rootResult{eval, exact, f.linePV(1)}
+				if exact && ((player && eval > bestValue) || (!player && eval < bestValue)) {
+					bestValue = eval
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	for _, f := range forks {
+		s.merge(f)
+	}
+	if s.aborted {
+		return 0, nil
+	}
+
+	best := 0
+	for i, r := range results {
+		if r.exact && ((player && r.eval > results[best].eval) || (!player && r.eval < results[best].eval)) {
+			best = i
+		}
+	}
+	s.rootTies = make([]Move, 0)
+	for _, i := range tiedResults(results, results[best].eval) {
+		s.rootTies = append(s.rootTies, children[i].move)
+	}
+	move := children[best].move
+	s.pv[0][0] = move
+	s.pvLength[0] = 1 + copy(s.pv[0][1:], results[best].pv)
+	s.table.store(key, depth, results[best].eval, boundExact, move)
+	return results[best].eval, &move
+}
+
+//tiedResults returns the indexes of the exact results with the given score
+func tiedResults(results []rootResult, eval int) []int {
+	tied := make([]int, 0)
+	for i, r := range results {
+		if r.exact && r.eval == eval {
+			tied = append(tied, i)
+		}
+	}
+	return tied
+}
+
+//fork creates a search for a worker sharing the limits, node counter and transposition table,
+//the move ordering tables are copied
+func (s *search) fork() *search {
+	return &search{
+		ctx:           s.ctx,
+		deadline:      s.deadline,
+		nodeLimit:     s.nodeLimit,
+		sharedNodes:   s.sharedNodes,
+		table:         s.table,
+		weights:       s.weights,
+		tablebase:     s.tablebase,
+		iteration:     s.iteration,
+		killers:       s.killers,
+		history:       s.history,
+		interruptible: s.interruptible,
+	}
+}
+
+//merge adds the counters of a finished worker search
+func (s *search) merge(f *search) {
+	s.nodes += f.nodes
+	s.quiescenceNodes += f.quiescenceNodes
+	s.tableHits += f.tableHits
+	s.tablebaseHits += f.tablebaseHits
+	s.cutoffs += f.cutoffs
+	s.firstMoveCutoffs += f.firstMoveCutoffs
+	if f.aborted {
+		s.aborted = true
+	}
+}
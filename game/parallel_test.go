@@ -0,0 +1,34 @@
+package game
+
+import (
+	"context"
+	"testing"
+)
+
+func TestParallelRootMatchesSerialScore(t *testing.T) {
+	for _, fen := range []string{
+		FEN(boardSetup(make(Board, height*width)), true),
+		"B:W27,28,31,32,33,34,36,37,38,39,40,41,42,43,45,46,47,48,49,50:B1,2,3,4,5,6,7,8,9,10,11,12,13,14,15,16,17,18,19,23",
+		"W:W25,27,34,37,39,40,41,43,44,46,48,49:B1,3,4,5,6,7,11,13,14,16,17,23,28",
+		"W:WK5,K25,K36:B23,K33,45",
+	} {
+		b, player, err := ParseFEN(fen)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, serial, err := (&MinimaxEngine{Depth: 4}).BestMove(context.Background(), b, player)
+		if err != nil {
+			t.Fatal(err)
+		}
+		m, parallel, err := (&MinimaxEngine{Depth: 4, Workers: 4}).BestMove(context.Background(), b, player)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := ParseMove(b, player, m.String()); err != nil {
+			t.Errorf("%s: parallel search played %s: %v", fen, m, err)
+		}
+		if parallel.Score != serial.Score {
+			t.Errorf("%s: parallel search scores %d, serial %d", fen, parallel.Score, serial.Score)
+		}
+	}
+}
@@ -0,0 +1,289 @@
+package game
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+//pdnGameType is the PDN game type of international draughts
+const pdnGameType = "20"
+
+//pdnLineLength is the maximum length of a move text line
+const pdnLineLength = 80
+
+//PDNGame is a game read from a PDN file together with its tags
+type PDNGame struct {
+	Tags map[string]string
+	Game *Game
+}
+
+//pdnResult returns the PDN result for the game state
+func pdnResult(s GameState) string {
+	switch s {
+	case GameStateWhiteWins:
+		return "2-0"
+	case GameStateRedWins:
+		return "0-2"
+	case GameStateDraw:
+		return "1-1"
+	}
+	return "*"
+}
+
+//pdnWinner returns the state of a PDN result won by a side, GameStateRunning for a draw or an unknown result
+func pdnWinner(result string) GameState {
+	switch result {
+	case "2-0", "1-0":
+		return GameStateWhiteWins
+	case "0-2", "0-1":
+		return GameStateRedWins
+	}
+	return GameStateRunning
+}
+
+//isPDNResult checks if the token is a game termination marker
+func isPDNResult(token string) bool {
+	switch token {
+	case "2-0", "0-2", "1-1", "0-0", "1-0", "0-1", "*":
+		return true
+	}
+	return false
+}
+
+//WritePDN writes the game record in the Portable Draughts Notation,
+//the given tags are added to (or replace) the default tags
+func (g *Game) WritePDN(w io.Writer, tags map[string]string) error {
+	started := g.started
+	if started.IsZero() {
+		started = time.Now()
+	}
+	all := map[string]string{
+		"Event":    "Checkers",
+		"Date":     started.Format("2006.01.02"),
+		"White":    "?",
+		"Black":    "?",
+		"Result":   pdnResult(g.state),
+		"GameType": pdnGameType,
+	}
+	if FEN(g.startBoard, g.startPlayer) != FEN(boardSetup(make(Board, height*width)), true) {
+		all["FEN"] = FEN(g.startBoard, g.startPlayer)
+	}
+	for k, v := range tags {
+		all[k] = v
+	}
+
+	order := []string{"Event", "Date", "White", "Black", "Result"}
+	rest := make([]string, 0)
+	for k := range all {
+		if k != "Event" && k != "Date" && k != "White" && k != "Black" && k != "Result" {
+			rest = append(rest, k)
+		}
+	}
+	sort.Strings(rest)
+	for _, k := range append(order, rest...) {
+		if _, err := fmt.Fprintf(w, "[%s %q]\n", k, all[k]); err != nil {
+			return err
+		}
+	}
+
+	tokens := make([]string, 0)
+	player := g.startPlayer
+	number := 1
+	for i, m := range g.History() {
+		//the move number is kept on the same line as the move
+		if player {
+			tokens = append(tokens, fmt.Sprintf("%d. %s", number, m.String()))
+		} else if i == 0 {
+			tokens = append(tokens, fmt.Sprintf("%d... %s", number, m.String()))
+		} else {
+			tokens = append(tokens, m.String())
+		}
+		if !player {
+			number++
+		}
+		player = !player
+	}
+	tokens = append(tokens, all["Result"])
+
+	var line strings.Builder
+	var text strings.Builder
+	text.WriteString("\n")
+	for _, t := range tokens {
+		if line.Len() > 0 && line.Len()+len(t)+1 > pdnLineLength {
+			text.WriteString(line.String() + "\n")
+			line.Reset()
+		}
+		if line.Len() > 0 {
+			line.WriteString(" ")
+		}
+		line.WriteString(t)
+	}
+	text.WriteString(line.String() + "\n\n")
+	_, err := io.WriteString(w, text.String())
+	return err
+}
+
+//ReadPDN reads all games from a PDN file and replays their moves,
+//an illegal move results in an error
+func ReadPDN(r io.Reader) ([]PDNGame, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	tokens, err := pdnTokens(string(data))
+	if err != nil {
+		return nil, err
+	}
+
+	games := make([]PDNGame, 0)
+	tags := make(map[string]string)
+	moves := make([]string, 0)
+	finish := func() error {
+		g, err := replayPDN(tags, moves)
+		if err != nil {
+			return fmt.Errorf("pdn game %d: %w", len(games)+1, err)
+		}
+		games = append(games, PDNGame{tags, g})
+		tags = make(map[string]string)
+		moves = make([]string, 0)
+		return nil
+	}
+	for _, t := range tokens {
+		switch {
+		case strings.HasPrefix(t, "["):
+			if len(moves) > 0 {
+				if err := finish(); err != nil {
+					return nil, err
+				}
+			}
+			name, value, err := parsePDNTag(t)
+			if err != nil {
+				return nil, err
+			}
+			tags[name] = value
+		case isPDNResult(t):
+			if err := finish(); err != nil {
+				return nil, err
+			}
+		default:
+			moves = append(moves, t)
+		}
+	}
+	if len(moves) > 0 || len(tags) > 0 {
+		if err := finish(); err != nil {
+			return nil, err
+		}
+	}
+	return games, nil
+}
+
+//replayPDN sets up a game from the tags and plays the moves
+func replayPDN(tags map[string]string, moves []string) (*Game, error) {
+	g := SetupGame()
+	if fen, ok := tags["FEN"]; ok {
+		b, player, err := ParseFEN(fen)
+		if err != nil {
+			return nil, err
+		}
+		g = SetupGameFromPosition(b, player)
+	}
+	g.Start()
+	for i, text := range moves {
+		if g.GameState() != GameStateRunning {
+			return nil, fmt.Errorf("move %d %q: game is already over", i+1, text)
+		}
+		m, err := ParseMove(g.board, g.player, text)
+		if err != nil {
+			return nil, fmt.Errorf("move %d: %w", i+1, err)
+		}
+		g.MakeMove(m)
+	}
+	return g, nil
+}
+
+//parsePDNTag parses a tag pair like `[Event "Checkers"]`
+func parsePDNTag(t string) (string, string, error) {
+	inner := strings.TrimSpace(strings.TrimSuffix(strings.TrimPrefix(t, "["), "]"))
+	i := strings.IndexAny(inner, " \t")
+	if i < 0 {
+		return "", "", fmt.Errorf("invalid pdn tag %q", t)
+	}
+	value, err := strconv.Unquote(strings.TrimSpace(inner[i:]))
+	if err != nil {
+		return "", "", fmt.Errorf("invalid pdn tag %q", t)
+	}
+	return inner[:i], value, nil
+}
+
+//pdnTokens splits the PDN text into tags, moves and results,
+//comments, variations, move numbers and annotations are dropped
+func pdnTokens(s string) ([]string, error) {
+	tokens := make([]string, 0)
+	for i := 0; i < len(s); {
+		c := s[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case c == '[':
+			//the quoted value may contain brackets, so the tag ends at the first `]` outside of quotes
+			end, quoted := i, false
+			for ; end < len(s); end++ {
+				if quoted && s[end] == '\\' {
+					end++
+				} else if s[end] == '"' {
+					quoted = !quoted
+				} else if s[end] == ']' && !quoted {
+					break
+				}
+			}
+			if end >= len(s) {
+				return nil, fmt.Errorf("unterminated pdn tag")
+			}
+			tokens = append(tokens, s[i:end+1])
+			i = end + 1
+		case c == '{':
+			end := strings.IndexByte(s[i:], '}')
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated pdn comment")
+			}
+			i += end + 1
+		case c == '(':
+			depth := 0
+			for ; i < len(s); i++ {
+				if s[i] == '(' {
+					depth++
+				} else if s[i] == ')' {
+					depth--
+					if depth == 0 {
+						break
+					}
+				}
+			}
+			if depth != 0 {
+				return nil, fmt.Errorf("unterminated pdn variation")
+			}
+			i++
+		default:
+			end := i
+			for end < len(s) && !strings.ContainsRune(" \t\n\r[{(", rune(s[end])) {
+				end++
+			}
+			t := s[i:end]
+			i = end
+			//move numbers like `12.` or `12...` may be glued to the move
+			if dot := strings.LastIndex(t, "."); dot >= 0 {
+				t = t[dot+1:]
+			}
+			t = strings.TrimRight(t, "!?")
+			if t == "" || strings.HasPrefix(t, "$") {
+				continue
+			}
+			tokens = append(tokens, t)
+		}
+	}
+	return tokens, nil
+}
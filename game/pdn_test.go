@@ -0,0 +1,65 @@
+package game
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestPDNRoundTrip(t *testing.T) {
+	tests := []struct {
+		name  string
+		fen   string
+		event string
+		moves []string
+	}{
+		{"brackets in a tag", "W:W31-50:B1-20", "Cup [final]", []string{"32-28", "19-23", "28x19", "14x23"}},
+		{"fen start", "W:WK47,K49:BK2,K4", "Endgame", []string{"47-42", "2-7", "42-47"}},
+		{"red moves first", "B:W31-50:B1-20", "Handicap", []string{"19-23", "32-28", "23x32", "37x28"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := startGame(t, tt.fen)
+			playMoves(t, g, tt.moves...)
+			var buf bytes.Buffer
+			if err := g.WritePDN(&buf, map[string]string{"Event": tt.event}); err != nil {
+				t.Fatal(err)
+			}
+			text := buf.String()
+			number := "1. "
+			if !g.startPlayer {
+				number = "1... "
+			}
+			if !strings.Contains(text, number+tt.moves[0]) {
+				t.Errorf("first move is not numbered %q:\n%s", number, text)
+			}
+
+			games, err := ReadPDN(&buf)
+			if err != nil {
+				t.Fatalf("%v in\n%s", err, text)
+			}
+			if len(games) != 1 {
+				t.Fatalf("read %d games, want 1", len(games))
+			}
+			read := games[0]
+			if read.Tags["Event"] != tt.event {
+				t.Errorf("event %q, want %q", read.Tags["Event"], tt.event)
+			}
+			if got, want := FEN(read.Game.startBoard, read.Game.startPlayer), FEN(g.startBoard, g.startPlayer); got != want {
+				t.Errorf("starts from %s, want %s", got, want)
+			}
+			if got, want := read.Game.FEN(), g.FEN(); got != want {
+				t.Errorf("ends in %s, want %s", got, want)
+			}
+			history := read.Game.History()
+			if len(history) != len(tt.moves) {
+				t.Fatalf("read %d moves, want %d", len(history), len(tt.moves))
+			}
+			for i, m := range history {
+				if m.String() != tt.moves[i] {
+					t.Errorf("move %d is %s, want %s", i+1, m.String(), tt.moves[i])
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,56 @@
+package game
+
+//KnownPerft are the published perft numbers of the starting position by depth
+var KnownPerft = []uint64{
+	1,
+	9,
+	81,
+	658,
+	4265,
+	27117,
+	167140,
+	1049442,
+	6483961,
+	41022423,
+	258895763,
+	1665861398,
+}
+
+//PerftSplit is the leaf node count below a single root move
+type PerftSplit struct {
+	Move  Move
+	Nodes uint64
+}
+
+//Perft counts the leaf nodes of the legal move tree with the given depth,
+//its used to verify the move generator against known numbers
+func Perft(b Board, player bool, depth int) uint64 {
+	if depth == 0 {
+		return 1
+	}
+	moves := b.getPossibleValidMovesForPlayer(player)
+	if depth == 1 {
+		return uint64(len(moves))
+	}
+	nodes := uint64(0)
+	for _, m := range moves {
+		next := b.copy()
+		unrollMove(&next, m, player, m.Depth)
+		nodes += Perft(next, !player, depth-1)
+	}
+	return nodes
+}
+
+//PerftDivide counts the leaf nodes like Perft but split by root move
+func PerftDivide(b Board, player bool, depth int) []PerftSplit {
+	split := make([]PerftSplit, 0)
+	if depth == 0 {
+		return split
+	}
+	for _, m := range b.getPossibleValidMovesForPlayer(player) {
+		next := b.copy()
+		unrollMove(&next, m, player, m.Depth)
+		split = append(split, PerftSplit{m, Perft(next, !player, depth-1)})
+	}
+	return split
+}
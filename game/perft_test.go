@@ -0,0 +1,65 @@
+package game
+
+import (
+	"sort"
+	"testing"
+)
+
+//perftTestDepth keeps the start position check fast enough for every test run
+const perftTestDepth = 6
+
+func TestPerftStartPosition(t *testing.T) {
+	b, player, err := ParseFEN("W:W31-50:B1-20")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for depth := 1; depth <= perftTestDepth; depth++ {
+		if nodes := Perft(b, player, depth); nodes != KnownPerft[depth] {
+			t.Errorf("perft(%d) = %d, expected %d", depth, nodes, KnownPerft[depth])
+		}
+	}
+}
+
+//TestMoveRules checks the legal moves of positions covering the capture rules
+func TestMoveRules(t *testing.T) {
+	tests := []struct {
+		name  string
+		fen   string
+		moves []string
+	}{
+		//a king may stop on any empty square behind the captured piece
+		{"flying king landing choice", "W:WK46:B28", []string{"46x10", "46x14", "46x19", "46x23", "46x5"}},
+		{"flying king landing after two captures", "W:WK46:B37,28", []string{"46x32x10", "46x32x14", "46x32x19", "46x32x23", "46x32x5"}},
+		//the capture taking the most pieces has to be played, 36x27 only takes one
+		{"majority capture", "W:W32,36:B27,28,18,31", []string{"32x23x12"}},
+		{"majority capture with a man", "W:W32:B27,17,18", []string{"32x21x12x23"}},
+		//a captured piece stays on the board until the capture is finished and can not be jumped again
+		{"no double jump", "W:W32:B27,28", []string{"32x21", "32x23"}},
+		//taking the same pieces in another order and ending on the same square is the same move
+		{"capture paths count once", "W:WK7:B12,34", []string{"7x18x40", "7x18x45"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, player, err := ParseFEN(tt.fen)
+			if err != nil {
+				t.Fatal(err)
+			}
+			moves := make([]string, 0)
+			for _, m := range b.getPossibleValidMovesForPlayer(player) {
+				moves = append(moves, m.String())
+			}
+			sort.Strings(moves)
+			if len(moves) != len(tt.moves) {
+				t.Fatalf("%s: moves %v, expected %v", tt.fen, moves, tt.moves)
+			}
+			for i := range moves {
+				if moves[i] != tt.moves[i] {
+					t.Fatalf("%s: moves %v, expected %v", tt.fen, moves, tt.moves)
+				}
+			}
+			if nodes := Perft(b, player, 1); nodes != uint64(len(tt.moves)) {
+				t.Errorf("%s: perft(1) = %d, expected %d", tt.fen, nodes, len(tt.moves))
+			}
+		})
+	}
+}
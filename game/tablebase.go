@@ -0,0 +1,543 @@
+package game
+
+import (
+	"bufio"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"sort"
+	"sync"
+	"time"
+)
+
+//tablebaseMagic starts every tablebase file
+const tablebaseMagic = "CKTB2"
+
+//maxTablebasePlies is the longest distance a tablebase entry can hold
+const maxTablebasePlies = 254
+
+//TablebaseOutcome is the result of a tablebase position for the player to move
+type TablebaseOutcome int
+
+const (
+	//TablebaseDraw means neither side can force a win
+	TablebaseDraw TablebaseOutcome = iota
+	//TablebaseWin means the player to move wins
+	TablebaseWin
+	//TablebaseLoss means the player to move loses
+	TablebaseLoss
+)
+
+//TablebaseResult is the exact value of a position with perfect play
+type TablebaseResult struct {
+	Outcome TablebaseOutcome
+	//Plies is the number of plies until the game is won, 0 for a draw
+	Plies int
+}
+
+func (r TablebaseResult) String() string {
+	switch r.Outcome {
+	case TablebaseWin:
+		return fmt.Sprintf("win in %d plies", r.Plies)
+	case TablebaseLoss:
+		return fmt.Sprintf("loss in %d plies", r.Plies)
+	}
+	return "draw"
+}
+
+//score turns the result of the player to move into a search score from whites perspective,
+//quicker wins and slower losses score higher
+func (r TablebaseResult) score(player bool) int {
+	score := 0
+	switch r.Outcome {
+	case TablebaseWin:
+		score = winScore - r.Plies
+	case TablebaseLoss:
+		score = -winScore + r.Plies
+	}
+	if !player {
+		return -score
+	}
+	return score
+}
+
+//Tablebase holds the exact values of all positions with up to Pieces pieces.
+//Every material signature has its own table with a byte per position: 0 is a draw (or an impossible position),
+//otherwise the byte is the number of plies to the end of the game plus one. An even number of plies means
+//the player to move loses, an odd number that it wins. The draw rules counting moves are not taken into account.
+type Tablebase struct {
+	Pieces int
+	tables map[material][]byte
+}
+
+//material is the signature of a position, the number of men and kings of both sides
+type material struct {
+	whiteMen, whiteKings, redMen, redKings int
+}
+
+func (m material) String() string {
+	return fmt.Sprintf("%dm%dk-%dm%dk", m.whiteMen, m.whiteKings, m.redMen, m.redKings)
+}
+
+//total is the number of pieces
+func (m material) total() int {
+	return m.whiteMen + m.whiteKings + m.redMen + m.redKings
+}
+
+//menSquares is the number of squares a man can stand on, all but its promotion row
+var menSquares = squareCount - width/2
+
+//groups returns the number of pieces of every group in the order they are indexed:
+//white men, red men, white kings, red kings
+func (m material) groups() [4]int {
+	return [4]int{m.whiteMen, m.redMen, m.whiteKings, m.redKings}
+}
+
+//groupSquares returns the number of squares the pieces of group g are indexed over.
+//Men are indexed over the squares they can stand on, kings over the squares left by the groups before.
+func (m material) groupSquares(g int) int {
+	if g < 2 {
+		return menSquares
+	}
+	counts, free := m.groups(), squareCount
+	for i := 0; i < g; i++ {
+		free -= counts[i]
+	}
+	return free
+}
+
+//size is the number of entries of the table, every placement of the groups with both players to move
+func (m material) size() int {
+	size := 2
+	for g, k := range m.groups() {
+		size *= binomial[m.groupSquares(g)][k]
+	}
+	return size
+}
+
+//binomial holds the binomial coefficients to rank the squares of a group of pieces
+var binomial = func() [squareCount + 1][squareCount + 1]int {
+	var c [squareCount + 1][squareCount + 1]int
+	for n := 0; n <= squareCount; n++ {
+		c[n][0] = 1
+		for k := 1; k <= n; k++ {
+			c[n][k] = c[n-1][k-1] + c[n-1][k]
+		}
+	}
+	return c
+}()
+
+//materials returns the signatures with up to pieces pieces in the order they have to be generated:
+//captures lead to fewer pieces and promotions to fewer men
+func materials(pieces int) []material {
+	all := make([]material, 0)
+	for wm := 0; wm <= pieces; wm++ {
+		for wk := 0; wm+wk <= pieces; wk++ {
+			for rm := 0; wm+wk+rm <= pieces; rm++ {
+				for rk := 0; wm+wk+rm+rk <= pieces; rk++ {
+					if wm+wk > 0 && rm+rk > 0 {
+						all = append(all, material{wm, wk, rm, rk})
+					}
+				}
+			}
+		}
+	}
+	sort.SliceStable(all, func(i, j int) bool {
+		if all[i].total() != all[j].total() {
+			return all[i].total() < all[j].total()
+		}
+		return all[i].whiteMen+all[i].redMen < all[j].whiteMen+all[j].redMen
+	})
+	return all
+}
+
+//tablebaseIndex returns the signature and the table index of a position.
+//Every group is ranked by the squares of its pieces, counting only the squares the group is indexed over.
+func tablebaseIndex(b Board, player bool) (material, int) {
+	var groups [4][]int
+	for n := 1; n <= squareCount; n++ {
+		_, c := CoordinateOfSquare(n)
+		f := b.must(c)
+		if f.isEmpty() {
+			continue
+		}
+		g := 0
+		if !f.isWhitePiece() {
+			g++
+		}
+		if f.isKing() {
+			g += 2
+		}
+		groups[g] = append(groups[g], n)
+	}
+	m := material{len(groups[0]), len(groups[2]), len(groups[1]), len(groups[3])}
+	var occupied [squareCount + 1]bool
+	index := 0
+	for g, squares := range groups {
+		rank := 0
+		for i, n := range squares {
+			rank += binomial[groupSquare(g, n, &occupied)][i+1]
+		}
+		for _, n := range squares {
+			occupied[n] = true
+		}
+		index = index*binomial[m.groupSquares(g)][len(squares)] + rank
+	}
+	index *= 2
+	if player {
+		index++
+	}
+	return m, index
+}
+
+//groupSquare returns the position of the square among the squares group g is indexed over
+func groupSquare(g int, n int, occupied *[squareCount + 1]bool) int {
+	switch g {
+	case 0:
+		//white men can not stand on 1-5
+		return n - 1 - width/2
+	case 1:
+		return n - 1
+	}
+	s := 0
+	for i := 1; i < n; i++ {
+		if !occupied[i] {
+			s++
+		}
+	}
+	return s
+}
+
+//tablebasePosition builds the position of a table index, false if it is impossible
+//because men of both sides share a square
+func tablebasePosition(m material, index int) (Board, bool, bool) {
+	player := index%2 == 1
+	index /= 2
+	counts := m.groups()
+	var ranks [4]int
+	for g := 3; g >= 0; g-- {
+		size := binomial[m.groupSquares(g)][counts[g]]
+		ranks[g] = index % size
+		index /= size
+	}
+	b := emptyBoard()
+	var occupied [squareCount + 1]bool
+	for g, k := range counts {
+		rank := ranks[g]
+		squares := make([]int, 0, k)
+		//the squares are recovered from the highest down
+		s := m.groupSquares(g) - 1
+		for i := k; i > 0; i-- {
+			for binomial[s][i] > rank {
+				s--
+			}
+			rank -= binomial[s][i]
+			squares = append(squares, squareOfGroup(g, s, &occupied))
+			s--
+		}
+		white, king := g%2 == 0, g >= 2
+		for _, n := range squares {
+			if occupied[n] {
+				return nil, false, false
+			}
+			occupied[n] = true
+			_, c := CoordinateOfSquare(n)
+			b.placePiece(c, white, king)
+		}
+	}
+	return b, player, true
+}
+
+//squareOfGroup is the inverse of groupSquare, it returns the square at position s among the squares of group g
+func squareOfGroup(g int, s int, occupied *[squareCount + 1]bool) int {
+	switch g {
+	case 0:
+		return s + 1 + width/2
+	case 1:
+		return s + 1
+	}
+	for n := 1; n <= squareCount; n++ {
+		if !occupied[n] {
+			if s == 0 {
+				return n
+			}
+			s--
+		}
+	}
+	return 0
+}
+
+//Probe returns the exact value of the position for the player to move,
+//false if the position has more pieces than the tablebase
+func (t *Tablebase) Probe(b Board, player bool) (TablebaseResult, bool) {
+	if t == nil {
+		return TablebaseResult{}, false
+	}
+	m := tablebaseMaterial(b)
+	if m.whiteMen+m.whiteKings == 0 || m.redMen+m.redKings == 0 {
+		//the player without pieces has lost
+		return TablebaseResult{TablebaseLoss, 0}, true
+	}
+	table, ok := t.tables[m]
+	if !ok {
+		return TablebaseResult{}, false
+	}
+	_, index := tablebaseIndex(b, player)
+	return decodeTablebaseValue(table[index]), true
+}
+
+//tablebaseMaterial returns the signature of the position without indexing it
+func tablebaseMaterial(b Board) material {
+	w, r, wk, rk := b.getCounts()
+	return material{w - wk, wk, r - rk, rk}
+}
+
+//decodeTablebaseValue turns a table entry into its result
+func decodeTablebaseValue(v byte) TablebaseResult {
+	if v == 0 {
+		return TablebaseResult{}
+	}
+	plies := int(v) - 1
+	if plies%2 == 0 {
+		return TablebaseResult{TablebaseLoss, plies}
+	}
+	return TablebaseResult{TablebaseWin, plies}
+}
+
+//value returns the table entry of a position, a side without pieces has lost
+func (t *Tablebase) value(b Board, player bool) byte {
+	if m := tablebaseMaterial(b); m.whiteMen+m.whiteKings == 0 || m.redMen+m.redKings == 0 {
+		return 1
+	}
+	m, index := tablebaseIndex(b, player)
+	return t.tables[m][index]
+}
+
+//tablebaseEntry holds what the generation knows about a position
+type tablebaseEntry struct {
+	//remaining counts the moves staying in the table whose value is not known yet
+	remaining uint8
+	//win is the shortest win by a move leaving the table, loss the longest loss
+	win, loss uint8
+	//invalid positions can not occur, forced ones have a capture pending
+	//and blocked ones can draw by a move leaving the table
+	invalid, forced, blocked bool
+}
+
+//GenerateTablebase computes all positions with up to pieces pieces by retrograde analysis.
+//The positions are first searched one move deep, their values then spread backwards from the
+//decided positions ordered by distance. Workers search the positions in parallel.
+func GenerateTablebase(pieces int, workers int) *Tablebase {
+	t := &Tablebase{Pieces: pieces, tables: make(map[material][]byte)}
+	for _, m := range materials(pieces) {
+		started := time.Now()
+		t.tables[m] = t.generate(m, maxOf(workers, 1))
+		wins, losses := 0, 0
+		for _, v := range t.tables[m] {
+			switch decodeTablebaseValue(v).Outcome {
+			case TablebaseWin:
+				wins++
+			case TablebaseLoss:
+				losses++
+			}
+		}
+		log.Printf("Tablebase %s: %d positions, %d wins, %d losses (%s)", m, m.size(), wins, losses, time.Since(started).Round(time.Millisecond))
+	}
+	return t
+}
+
+//generate computes the table of a signature, the tables it depends on have to be complete
+func (t *Tablebase) generate(m material, workers int) []byte {
+	size := m.size()
+	values := make([]byte, size)
+	entries := make([]tablebaseEntry, size)
+
+	var wg sync.WaitGroup
+	chunk := (size + workers - 1) / workers
+	for w := 0; w < workers; w++ {
+		from, to := w*chunk, minOf((w+1)*chunk, size)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := from; i < to; i++ {
+				entries[i] = t.searchEntry(m, i)
+			}
+		}()
+	}
+	wg.Wait()
+
+	//buckets holds the positions by the distance they are decided at, a position can be added more than once
+	var buckets [maxTablebasePlies + 1][]int
+	push := func(i int, plies int) {
+		if plies <= maxTablebasePlies {
+			buckets[plies] = append(buckets[plies], i)
+		}
+	}
+	for i, e := range entries {
+		switch {
+		case e.invalid:
+		case e.win > 0:
+			push(i, int(e.win))
+		case e.remaining == 0 && !e.blocked:
+			push(i, int(e.loss))
+		}
+	}
+	for plies := 0; plies <= maxTablebasePlies; plies++ {
+		for _, i := range buckets[plies] {
+			if values[i] != 0 {
+				continue
+			}
+			values[i] = byte(plies + 1)
+			b, player, _ := tablebasePosition(m, i)
+			for _, q := range predecessors(b, player) {
+				_, qi := tablebaseIndex(q, !player)
+				e := &entries[qi]
+				if e.invalid || e.forced || values[qi] != 0 {
+					continue
+				}
+				if plies%2 == 0 {
+					//the move into a lost position wins
+					push(qi, plies+1)
+					continue
+				}
+				e.remaining--
+				if e.remaining == 0 && e.win == 0 && !e.blocked {
+					push(qi, maxOf(int(e.loss), plies+1))
+				}
+			}
+		}
+		buckets[plies] = nil
+	}
+	return values
+}
+
+//searchEntry looks at the moves of a position, moves leaving the table are valued by the smaller tables
+func (t *Tablebase) searchEntry(m material, index int) tablebaseEntry {
+	b, player, ok := tablebasePosition(m, index)
+	if !ok {
+		return tablebaseEntry{invalid: true}
+	}
+	e := tablebaseEntry{}
+	moves := b.getPossibleValidMovesForPlayer(player)
+	if len(moves) == 0 {
+		//no move left loses right away
+		return tablebaseEntry{remaining: 0}
+	}
+	e.forced = moves[0].Takes != nil
+	for _, mv := range moves {
+		next := b.copy()
+		unrollMove(&next, mv, player, mv.Depth)
+		if nm, _ := tablebaseIndex(next, !player); nm == m {
+			e.remaining++
+			continue
+		}
+		v := t.value(next, !player)
+		if v == 0 {
+			e.blocked = true
+			continue
+		}
+		plies := int(v)
+		if (plies-1)%2 == 0 {
+			//the opponent loses after the move
+			if e.win == 0 || plies < int(e.win) {
+				e.win = uint8(plies)
+			}
+		} else if plies > int(e.loss) {
+			e.loss = uint8(plies)
+		}
+	}
+	return e
+}
+
+//predecessors returns the positions a quiet move of the player who just moved leads from to the board,
+//captures and promotions leave the table and are not taken back
+func predecessors(b Board, player bool) []Board {
+	mover := !player
+	boards := make([]Board, 0)
+	for _, from := range b.allPiecesFor(mover) {
+		f := b.must(from)
+		var steps []Coordinate
+		if f.isKing() {
+			for _, d := range [][2]int{{-1, -1}, {-1, 1}, {1, -1}, {1, 1}} {
+				for c := from.Shift(d[0], d[1]); ; c = c.Shift(d[0], d[1]) {
+					ok, t := b.at(c)
+					if !ok || !t.isEmpty() {
+						break
+					}
+					steps = append(steps, c)
+				}
+			}
+		} else {
+			//men move forward, so they came from behind
+			back := 1
+			if !mover {
+				back = -1
+			}
+			for _, dc := range []int{-1, 1} {
+				if ok, t := b.at(from.Shift(back, dc)); ok && t.isEmpty() {
+					steps = append(steps, from.Shift(back, dc))
+				}
+			}
+		}
+		for _, c := range steps {
+			q := b.copy()
+			q[IndexOf(c.Row, c.Col)] = f
+			q[IndexOf(from.Row, from.Col)] = set(0, Empty)
+			boards = append(boards, q)
+		}
+	}
+	return boards
+}
+
+//Encode writes the tablebase compressed with gzip
+func (t *Tablebase) Encode(w io.Writer) error {
+	z := gzip.NewWriter(w)
+	out := bufio.NewWriter(z)
+	if _, err := fmt.Fprintf(out, "%s %d\n", tablebaseMagic, t.Pieces); err != nil {
+		return err
+	}
+	for _, m := range materials(t.Pieces) {
+		if _, err := out.Write(t.tables[m]); err != nil {
+			return err
+		}
+	}
+	if err := out.Flush(); err != nil {
+		return err
+	}
+	return z.Close()
+}
+
+//ReadTablebase reads a tablebase written by Encode
+func ReadTablebase(r io.Reader) (*Tablebase, error) {
+	z, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("invalid tablebase: %w", err)
+	}
+	in := bufio.NewReader(z)
+	t := &Tablebase{tables: make(map[material][]byte)}
+	var magic string
+	if _, err := fmt.Fscanf(in, "%s %d\n", &magic, &t.Pieces); err != nil || magic != tablebaseMagic {
+		return nil, errors.New("invalid tablebase: unknown header")
+	}
+	for _, m := range materials(t.Pieces) {
+		table := make([]byte, m.size())
+		if _, err := io.ReadFull(in, table); err != nil {
+			return nil, fmt.Errorf("invalid tablebase: table %s: %w", m, err)
+		}
+		t.tables[m] = table
+	}
+	return t, nil
+}
+
+//LoadTablebase reads the tablebase file at path
+func LoadTablebase(path string) (*Tablebase, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return ReadTablebase(f)
+}
@@ -0,0 +1,163 @@
+package game
+
+import (
+	"bytes"
+	"context"
+	"math/rand"
+	"sync"
+	"testing"
+)
+
+var (
+	twoPieceTablebase     *Tablebase
+	twoPieceTablebaseOnce sync.Once
+)
+
+// testTablebase generates the tablebase of all positions with up to two pieces once
+func testTablebase() *Tablebase {
+	twoPieceTablebaseOnce.Do(func() {
+		twoPieceTablebase = GenerateTablebase(2, 1)
+	})
+	return twoPieceTablebase
+}
+
+func TestTablebaseIndexRoundTrip(t *testing.T) {
+	for _, m := range materials(3) {
+		for i := 0; i < m.size(); i++ {
+			b, player, ok := tablebasePosition(m, i)
+			if !ok {
+				continue
+			}
+			if bm, bi := tablebaseIndex(b, player); bm != m || bi != i {
+				t.Fatalf("%s index %d: %s has index %d of %s", m, i, FEN(b, player), bi, bm)
+			}
+		}
+	}
+	//larger signatures are too big to walk, random positions of them have to find their way back
+	rng := rand.New(rand.NewSource(1))
+	for _, m := range materials(5) {
+		if m.total() < 4 {
+			continue
+		}
+		for n := 0; n < 200; n++ {
+			i := rng.Intn(m.size())
+			b, player, ok := tablebasePosition(m, i)
+			if !ok {
+				continue
+			}
+			if bm, bi := tablebaseIndex(b, player); bm != m || bi != i {
+				t.Fatalf("%s index %d: %s has index %d of %s", m, i, FEN(b, player), bi, bm)
+			}
+		}
+	}
+}
+
+func TestTablebaseProbe(t *testing.T) {
+	tb := testTablebase()
+	var buf bytes.Buffer
+	if err := tb.Encode(&buf); err != nil {
+		t.Fatal(err)
+	}
+	read, err := ReadTablebase(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		fen  string
+		want TablebaseResult
+	}{
+		{"W:WK46:B28", TablebaseResult{TablebaseWin, 1}},
+		{"B:WK46:B28", TablebaseResult{TablebaseLoss, 6}},
+		{"W:W11:B16", TablebaseResult{TablebaseWin, 11}},
+		{"W:W46:B5", TablebaseResult{}},
+	}
+	for _, tt := range tests {
+		b, player, err := ParseFEN(tt.fen)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, probed := range []*Tablebase{tb, read} {
+			if r, ok := probed.Probe(b, player); !ok || r != tt.want {
+				t.Errorf("%s: %v, want %v", tt.fen, r, tt.want)
+			}
+		}
+	}
+	b, player, _ := ParseFEN("W:WK46,47:B28")
+	if _, ok := tb.Probe(b, player); ok {
+		t.Error("a position with more pieces than the tablebase is probed")
+	}
+}
+
+// kingTables are the three piece tables of the draw rule test, generated on top of the two piece tablebase
+var (
+	kingTables     *Tablebase
+	kingTablesOnce sync.Once
+)
+
+// testKingTablebase adds two kings against a king and a man and king against a king to the two piece tablebase
+func testKingTablebase() *Tablebase {
+	kingTablesOnce.Do(func() {
+		kingTables = GenerateTablebase(2, 1)
+		kingTables.Pieces = 3
+		for _, m := range []material{{0, 2, 0, 1}, {1, 1, 0, 1}} {
+			kingTables.tables[m] = kingTables.generate(m, 4)
+		}
+	})
+	return kingTables
+}
+
+func TestTablebaseDrawRules(t *testing.T) {
+	tb := testKingTablebase()
+	s := &search{ctx: context.Background(), tablebase: tb}
+	tests := []struct {
+		name string
+		fen  string
+		//spare is the number of king moves left after the win, negative if the draw comes first
+		spare int
+		win   bool
+	}{
+		{"kings win in time", "W:WK1,K2:BK45", 0, true},
+		{"kings win too late", "W:WK1,K2:BK45", -1, false},
+		//the white man has not moved while the kings did, moving it resets the count
+		{"man on the board", "W:WK2,6:BK50", -1, true},
+	}
+	for _, tt := range tests {
+		b, player, err := ParseFEN(tt.fen)
+		if err != nil {
+			t.Fatal(err)
+		}
+		r, ok := tb.Probe(b, player)
+		if !ok || r.Outcome != TablebaseWin {
+			t.Fatalf("%s is %v, want a win", tt.fen, r)
+		}
+		draws := drawCounter{kingMoves: kingMovesLimit - r.Plies - tt.spare}
+		score, ok := s.probeTablebase(b, player, draws, 0)
+		if !ok || (score == r.score(player)) != tt.win || (!tt.win && score != 0) {
+			t.Errorf("%s: %s with %d king moves played scores %d", tt.name, r, draws.kingMoves, score)
+		}
+	}
+	//a small endgame keeps its own limit with men on the board
+	b, player, _ := ParseFEN("W:WK2,6:BK50")
+	r, _ := tb.Probe(b, player)
+	draws := drawCounter{endgamePieces: 3, endgameMoves: shortEndgameMovesLimit - r.Plies + 1}
+	if score, _ := s.probeTablebase(b, player, draws, 0); score != 0 {
+		t.Errorf("a win taking longer than the two against one king endgame allows scores %d, want a draw", score)
+	}
+}
+
+func TestSearchMatchesTablebase(t *testing.T) {
+	tb := testTablebase()
+	b, player, err := ParseFEN("W:W11:B16")
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, _ := tb.Probe(b, player)
+	//the search adds the plies before it reaches the tablebase, like its own wins
+	_, info, err := (&MinimaxEngine{Depth: 4, Tablebase: tb}).BestMove(context.Background(), b, player)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Score != r.score(player) {
+		t.Errorf("search scores %d, tablebase %v (%d)", info.Score, r, r.score(player))
+	}
+}
@@ -0,0 +1,174 @@
+package game
+
+//boardTerm is an evaluation term counting the pieces of both sides matching a pattern
+type boardTerm struct {
+	name   string
+	weight func(*Weights) *int
+	count  func(Board) (white int, red int)
+}
+
+//moveTerm is an evaluation term scoring a possible move, the best move of each side counts
+type moveTerm struct {
+	name   string
+	weight func(*Weights) *int
+	value  func(b Board, m Move, player bool) int
+}
+
+//boardTerms are the board terms of the evaluation in the order of Weights
+var boardTerms = []boardTerm{
+	{"piece", func(w *Weights) *int { return &w.Piece }, Board.getPieceCount},
+	{"king", func(w *Weights) *int { return &w.King }, Board.getKingCount},
+	{"goldenStone", func(w *Weights) *int { return &w.GoldenStone }, Board.getGoldenStoneCount},
+	{"middleBox", func(w *Weights) *int { return &w.MiddleBox }, Board.getMiddleBoxCount},
+	{"middle", func(w *Weights) *int { return &w.Middle }, Board.getMiddleCount},
+	{"leftSide", func(w *Weights) *int { return &w.LeftSide }, Board.getLeftSideCount},
+	{"rightSide", func(w *Weights) *int { return &w.RightSide }, Board.getRightSideCount},
+	{"protection", func(w *Weights) *int { return &w.Protection }, Board.getProtectionCount},
+	{"stuckPiece", func(w *Weights) *int { return &w.StuckPiece }, Board.getStuckPieces},
+	{"stuckKing", func(w *Weights) *int { return &w.StuckKing }, Board.getStuckKings},
+	{"leggardAndGrape", func(w *Weights) *int { return &w.LeggardAndGrape }, Board.getLeggardAndGrapeCount},
+	{"fullSquare", func(w *Weights) *int { return &w.FullSquare }, Board.getFullSquares},
+	{"halfSquare", func(w *Weights) *int { return &w.HalfSquare }, Board.getHalfSquare},
+	{"fullGate", func(w *Weights) *int { return &w.FullGate }, Board.getFullGates},
+	{"halfGate", func(w *Weights) *int { return &w.HalfGate }, Board.getHalfGates},
+	{"pincer", func(w *Weights) *int { return &w.Pincer }, Board.getPincers},
+	{"largestField", func(w *Weights) *int { return &w.LargestField }, Board.getLargestConnectedField},
+	{"vulnerablePiece", func(w *Weights) *int { return &w.VulnerablePiece }, Board.getVulnerablePiecesCount},
+	{"suicidalPiece", func(w *Weights) *int { return &w.SuicidalPiece }, Board.getSuicidalPiecesCount},
+}
+
+//moveTerms are the move terms of the evaluation in the order of Weights
+var moveTerms = []moveTerm{
+	{"savingMove", func(w *Weights) *int { return &w.SavingMove }, predicate(heuristicSavingMove)},
+	{"protectingMove", func(w *Weights) *int { return &w.ProtectingMove }, predicate(heuristicProtectingMove)},
+	{"kingMove", func(w *Weights) *int { return &w.KingMove }, predicate(heuristicMoveLeadsToKing)},
+	{"winningMove", func(w *Weights) *int { return &w.WinningMove }, predicate(heuristicMoveLeadsToWin)},
+	{"capture", func(w *Weights) *int { return &w.Capture }, captureTerm},
+	{"captureStep", func(w *Weights) *int { return &w.CaptureStep }, captureStepTerm},
+	{"takenMove", func(w *Weights) *int { return &w.TakenMove }, predicate(heuristicGetsTaken)},
+	{"looseProtection", func(w *Weights) *int { return &w.LooseProtection }, predicate(heuristicLooseProtectingMove)},
+}
+
+//predicate turns a move heuristic into a move term counting 1 if it applies
+func predicate(h func(b Board, m Move, player bool) bool) func(Board, Move, bool) int {
+	return func(b Board, m Move, player bool) int {
+		if h(b, m, player) {
+			return 1
+		}
+		return 0
+	}
+}
+
+//captureTerm counts 1 for a capture
+func captureTerm(b Board, m Move, player bool) int {
+	if m.Depth > 0 {
+		return 1
+	}
+	return 0
+}
+
+//captureStepTerm counts the steps of a capture chain
+func captureStepTerm(b Board, m Move, player bool) int {
+	if m.Depth > 0 {
+		return m.Depth
+	}
+	return 0
+}
+
+//getPieceCount counts all pieces including the kings
+func (b Board) getPieceCount() (white int, red int) {
+	white, red, _, _ = b.getCounts()
+	return white, red
+}
+
+//getKingCount counts the kings
+func (b Board) getKingCount() (white int, red int) {
+	_, _, white, red = b.getCounts()
+	return white, red
+}
+
+//getStuckPieces counts the pieces that can not move
+func (b Board) getStuckPieces() (white int, red int) {
+	white, red, _, _ = b.getStuckPiecesCount()
+	return white, red
+}
+
+//getStuckKings counts the kings that can not move
+func (b Board) getStuckKings() (white int, red int) {
+	_, _, white, red = b.getStuckPiecesCount()
+	return white, red
+}
+
+//moveValues fills values with the move terms of the move, terms weighted 0 by wt are left 0 (nil wt computes all)
+func (b Board) moveValues(m Move, player bool, wt *Weights, values []int) {
+	for i, t := range moveTerms {
+		values[i] = 0
+		if wt == nil || *t.weight(wt) != 0 {
+			values[i] = t.value(b, m, player)
+		}
+	}
+}
+
+//moveWeight is the weighted sum of the move term values
+func moveWeight(values []int, wt *Weights) int {
+	weight := 0
+	for i, t := range moveTerms {
+		weight += values[i] * *t.weight(wt)
+	}
+	return weight
+}
+
+//betterMove reports if a move with the weight and term values beats the best move so far.
+//Equal weights are decided by the values, so the choice does not depend on the order of the moves
+//and a mirrored board reports the same terms.
+func betterMove(weight int, values []int, bestWeight int, best []int) bool {
+	if weight != bestWeight {
+		return weight > bestWeight
+	}
+	for i := range values {
+		if values[i] != best[i] {
+			return values[i] > best[i]
+		}
+	}
+	return false
+}
+
+//bestMoveValues returns the move term values of the best move of each side,
+//all 0 if no move weighs more than 0
+func (b Board) bestMoveValues(wt *Weights) (white []int, red []int) {
+	values := make([]int, len(moveTerms))
+	for _, p := range []bool{true, false} {
+		best, bestWeight := make([]int, len(moveTerms)), 0
+		for _, m := range b.getPossibleValidMovesForPlayer(p) {
+			b.moveValues(m, p, wt, values)
+			if weight := moveWeight(values, wt); betterMove(weight, values, bestWeight, best) {
+				copy(best, values)
+				bestWeight = weight
+			}
+		}
+		if p {
+			white = best
+		} else {
+			red = best
+		}
+	}
+	return white, red
+}
+
+//tunableWeight is a weight together with its name
+type tunableWeight struct {
+	name  string
+	value *int
+}
+
+//tunableWeights returns all weights of w in the order of Weights
+func tunableWeights(w *Weights) []tunableWeight {
+	weights := make([]tunableWeight, 0, len(boardTerms)+len(moveTerms)+1)
+	for _, t := range boardTerms {
+		weights = append(weights, tunableWeight{t.name, t.weight(w)})
+	}
+	for _, t := range moveTerms {
+		weights = append(weights, tunableWeight{t.name, t.weight(w)})
+	}
+	return append(weights, tunableWeight{"bestMove", &w.BestMove})
+}
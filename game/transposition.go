@@ -0,0 +1,79 @@
+package game
+
+import "sync"
+
+//tableLocks is the number of locks guarding the table entries, entries share a lock by index
+const tableLocks = 64
+
+//defaultTableSize is the default number of transposition table entries
+const defaultTableSize = 1 << 18
+
+//bound tells how a stored score relates to the real score of a position
+type bound uint8
+
+const (
+	//boundExact is the exact score
+	boundExact bound = iota
+	//boundLower means the real score is at least the stored one (beta cutoff)
+	boundLower
+	//boundUpper means the real score is at most the stored one (no move reached alpha)
+	boundUpper
+)
+
+//ttEntry is a search result stored in the transposition table
+type ttEntry struct {
+	key   uint64
+	depth int
+	score int
+	bound bound
+	move  Move
+	used  bool
+	//generation is the search that stored the entry
+	generation uint32
+}
+
+//transpositionTable is a fixed size hash table of search results indexed by zobrist hash,
+//its safe to be shared by the workers of a parallel search
+type transpositionTable struct {
+	entries []ttEntry
+	mask    uint64
+	locks   [tableLocks]sync.Mutex
+	//generation is the current search, entries of earlier searches count as empty
+	generation uint32
+}
+
+//newTranspositionTable creates a table with size entries rounded down to a power of two
+func newTranspositionTable(size int) *transpositionTable {
+	n := 1
+	for n*2 <= size {
+		n *= 2
+	}
+	return &transpositionTable{entries: make([]ttEntry, n), mask: uint64(n - 1)}
+}
+
+//probe looks up the position
+func (t *transpositionTable) probe(key uint64) (ttEntry, bool) {
+	i := key & t.mask
+	t.locks[i%tableLocks].Lock()
+	e := t.entries[i]
+	t.locks[i%tableLocks].Unlock()
+	return e, e.used && e.key == key && e.generation == t.generation
+}
+
+//newGeneration empties the table for a new search without clearing every entry,
+//so the result of a search does not depend on the positions searched before
+func (t *transpositionTable) newGeneration() {
+	t.generation++
+}
+
+//store saves a search result, deeper results of the same position are kept
+func (t *transpositionTable) store(key uint64, depth int, score int, b bound, move Move) {
+	i := key & t.mask
+	t.locks[i%tableLocks].Lock()
+	defer t.locks[i%tableLocks].Unlock()
+	e := &t.entries[i]
+	if e.used && e.key == key && e.generation == t.generation && e.depth > depth {
+		return
+	}
+	*e = ttEntry{key, depth, score, b, move, true, t.generation}
+}
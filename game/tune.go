@@ -0,0 +1,341 @@
+package game
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"math/rand"
+	"strings"
+	"sync"
+)
+
+//LabeledPosition is a position together with the result of the game it occurred in
+type LabeledPosition struct {
+	Board  Board
+	Player bool
+	//Result is the outcome from whites perspective, 1 for a white win, 0.5 for a draw and 0 for a red win
+	Result float64
+}
+
+//labelResults maps the PDN results to the labels
+var labelResults = map[string]float64{"2-0": 1, "1-1": 0.5, "0-2": 0}
+
+//WriteLabeledPositions writes one position per line as FEN followed by the PDN result
+func WriteLabeledPositions(w io.Writer, positions []LabeledPosition) error {
+	out := bufio.NewWriter(w)
+	for _, p := range positions {
+		result := "1-1"
+		if p.Result > 0.5 {
+			result = "2-0"
+		} else if p.Result < 0.5 {
+			result = "0-2"
+		}
+		if _, err := fmt.Fprintf(out, "%s %s\n", FEN(p.Board, p.Player), result); err != nil {
+			return err
+		}
+	}
+	return out.Flush()
+}
+
+//ReadLabeledPositions reads positions written by WriteLabeledPositions, empty lines are skipped
+func ReadLabeledPositions(r io.Reader) ([]LabeledPosition, error) {
+	positions := make([]LabeledPosition, 0)
+	scanner := bufio.NewScanner(r)
+	line := 0
+	for scanner.Scan() {
+		line++
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 {
+			continue
+		}
+		if len(fields) != 2 {
+			return nil, fmt.Errorf("line %d: expected a FEN and a result", line)
+		}
+		result, ok := labelResults[fields[1]]
+		if !ok {
+			return nil, fmt.Errorf("line %d: invalid result %q", line, fields[1])
+		}
+		b, player, err := ParseFEN(fields[0])
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		positions = append(positions, LabeledPosition{b, player, result})
+	}
+	return positions, scanner.Err()
+}
+
+//SelfPlayOptions configure the generation of labeled positions
+type SelfPlayOptions struct {
+	//Games is the number of games to play
+	Games int
+	//Depth is the search depth of the engines
+	Depth int
+	//RandomPlies are played randomly at the start of each game so the games differ
+	RandomPlies int
+	//MaxPlies ends a game as a draw, 0 means no limit
+	MaxPlies int
+	//Seed makes the generation repeatable
+	Seed int64
+	//Workers is the number of games played in parallel
+	Workers int
+	//Weights are the evaluation weights of the engines, nil means DefaultWeights
+	Weights *Weights
+}
+
+//SelfPlay plays engine games and labels their quiet positions (no capture pending) with the result.
+//The positions of the random opening plies are left out.
+func SelfPlay(opts SelfPlayOptions) []LabeledPosition {
+	workers := maxOf(opts.Workers, 1)
+	games := make([][]LabeledPosition, opts.Games)
+	jobs := make(chan int, opts.Games)
+	for i := range games {
+		jobs <- i
+	}
+	close(jobs)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				games[i] = selfPlayGame(opts, opts.Seed+int64(i))
+			}
+		}()
+	}
+	wg.Wait()
+	positions := make([]LabeledPosition, 0)
+	for _, g := range games {
+		positions = append(positions, g...)
+	}
+	return positions
+}
+
+//selfPlayGame plays a single game and returns its labeled positions
+func selfPlayGame(opts SelfPlayOptions, seed int64) []LabeledPosition {
+	rng := rand.New(rand.NewSource(seed))
+	g := SetupGame()
+	for _, player := range []bool{true, false} {
+		e := NewMinimaxEngine()
+		e.Depth = opts.Depth
+		e.Weights = opts.Weights
+		//a seed of 0 would make the engine deterministic
+		e.Seed = rng.Int63() | 1
+		g.SetEngine(player, e)
+	}
+	g.Start()
+	positions := make([]LabeledPosition, 0)
+	for g.GameState() == GameStateRunning {
+		if opts.MaxPlies > 0 && len(g.history) >= opts.MaxPlies {
+			break
+		}
+		if len(g.history) < opts.RandomPlies {
+			moves := g.board.getPossibleValidMovesForPlayer(g.player)
+			g.MakeMove(moves[rng.Intn(len(moves))])
+			continue
+		}
+		if moves := g.board.getPossibleValidMovesForPlayer(g.player); moves[0].Takes == nil {
+			positions = append(positions, LabeledPosition{g.board.copy(), g.player, 0})
+		}
+		if err := g.MakeAIMove(); err != nil {
+			log.Printf("Self play game %d stopped: %v", seed, err)
+			return nil
+		}
+	}
+	result := 0.5
+	switch g.GameState() {
+	case GameStateWhiteWins:
+		result = 1
+	case GameStateRedWins:
+		result = 0
+	}
+	for i := range positions {
+		positions[i].Result = result
+	}
+	return positions
+}
+
+//TuneOptions configure the weight tuning
+type TuneOptions struct {
+	//Iterations is the maximum number of passes over all weights, 0 means until no weight improves
+	Iterations int
+	//Fixed are the names of the weights that are not changed
+	Fixed []string
+	//K scales the evaluation in the logistic function, 0 fits it to the start weights
+	K float64
+}
+
+//TuneResult are the tuned weights together with the prediction error before and after
+type TuneResult struct {
+	Weights    Weights
+	K          float64
+	StartError float64
+	Error      float64
+}
+
+//evalFeatures are the term counts of a position, the evaluation with any weights can be computed from them
+type evalFeatures struct {
+	//board holds white minus red for every board term
+	board []int
+	//moves holds the move term values of every possible move of white and red
+	moves [2][][]int
+}
+
+//features extracts the term counts of a board that is not decided yet
+func (b Board) features() (evalFeatures, bool) {
+	if _, ok := b.terminalScore(); ok {
+		return evalFeatures{}, false
+	}
+	f := evalFeatures{board: make([]int, len(boardTerms))}
+	for i, t := range boardTerms {
+		white, red := t.count(b)
+		f.board[i] = white - red
+	}
+	for side, p := range []bool{true, false} {
+		for _, m := range b.getPossibleValidMovesForPlayer(p) {
+			values := make([]int, len(moveTerms))
+			b.moveValues(m, p, nil, values)
+			f.moves[side] = append(f.moves[side], values)
+		}
+	}
+	return f, true
+}
+
+//evaluate computes the evaluation of the position for the given weights the same way as Board.evaluateTerms
+//without counting the board again
+func (f evalFeatures) evaluate(wt *Weights) int {
+	score := 0
+	for i, t := range boardTerms {
+		score += f.board[i] * *t.weight(wt)
+	}
+	best := [2]int{}
+	for side, moves := range f.moves {
+		for _, values := range moves {
+			best[side] = maxOf(best[side], moveWeight(values, wt))
+		}
+	}
+	return score + (best[0]*wt.BestMove - best[1]*wt.BestMove)
+}
+
+//labeledFeatures is a position reduced to its features
+type labeledFeatures struct {
+	features evalFeatures
+	result   float64
+}
+
+//predictionError is the mean squared difference between the results and the win probability predicted by the evaluation
+func predictionError(positions []labeledFeatures, wt *Weights, k float64) float64 {
+	sum := 0.0
+	for _, p := range positions {
+		d := p.result - winProbability(p.features.evaluate(wt), k)
+		sum += d * d
+	}
+	return sum / float64(len(positions))
+}
+
+//winProbability is the logistic function mapping an evaluation to the expected result for white
+func winProbability(score int, k float64) float64 {
+	return 1 / (1 + math.Pow(10, -k*float64(score)/400))
+}
+
+//fitK finds the scale of the logistic function that best predicts the results with the given weights
+func fitK(positions []labeledFeatures, wt *Weights) float64 {
+	best, bestErr := 1.0, math.Inf(1)
+	//coarse logarithmic scan, then refined around the best value
+	for k := 0.001; k < 100; k *= 1.25 {
+		if err := predictionError(positions, wt, k); err < bestErr {
+			best, bestErr = k, err
+		}
+	}
+	for step := best / 4; step > best/1000; step /= 2 {
+		for _, k := range []float64{best - step, best + step} {
+			if err := predictionError(positions, wt, k); k > 0 && err < bestErr {
+				best, bestErr = k, err
+			}
+		}
+	}
+	return best
+}
+
+//Tune fits the weights to the labeled positions by minimizing the prediction error (Texel tuning).
+//Each pass tries to move every weight up and down and keeps the changes lowering the error,
+//the step size starts at an eighth of the weight and halves down to 1 when neither direction helps.
+func Tune(positions []LabeledPosition, start Weights, opts TuneOptions) (TuneResult, error) {
+	data := make([]labeledFeatures, 0, len(positions))
+	for _, p := range positions {
+		if f, ok := p.Board.features(); ok {
+			data = append(data, labeledFeatures{f, p.Result})
+		}
+	}
+	if len(data) == 0 {
+		return TuneResult{}, fmt.Errorf("no undecided positions to tune with")
+	}
+	w := start
+	weights := tunableWeights(&w)
+	fixed := make(map[string]bool)
+	for _, name := range opts.Fixed {
+		known := false
+		for _, t := range weights {
+			known = known || t.name == name
+		}
+		if !known {
+			return TuneResult{}, fmt.Errorf("unknown weight %q", name)
+		}
+		fixed[name] = true
+	}
+	k := opts.K
+	if k <= 0 {
+		k = fitK(data, &w)
+	}
+
+	steps := make([]int, len(weights))
+	for i, t := range weights {
+		steps[i] = maxOf(1, abs(*t.value)/8)
+	}
+	startErr := predictionError(data, &w, k)
+	bestErr := startErr
+	log.Printf("Tuning %d positions | K: %.4f | error: %.6f", len(data), k, bestErr)
+	for pass := 1; opts.Iterations <= 0 || pass <= opts.Iterations; pass++ {
+		improved := false
+		for i, t := range weights {
+			if fixed[t.name] {
+				continue
+			}
+			for {
+				old := *t.value
+				changed := false
+				for _, d := range []int{steps[i], -steps[i]} {
+					*t.value = old + d
+					if err := predictionError(data, &w, k); err < bestErr {
+						bestErr = err
+						changed = true
+						break
+					}
+				}
+				if changed {
+					improved = true
+					continue
+				}
+				*t.value = old
+				if steps[i] == 1 {
+					break
+				}
+				steps[i] /= 2
+			}
+		}
+		log.Printf("Tuning pass %d | error: %.6f", pass, bestErr)
+		if !improved {
+			break
+		}
+	}
+	return TuneResult{w, k, startErr, bestErr}, nil
+}
+
+//abs returns the absolute value
+func abs(i int) int {
+	if i < 0 {
+		return -i
+	}
+	return i
+}
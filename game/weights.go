@@ -0,0 +1,162 @@
+package game
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+)
+
+//Weights are the factors of the evaluation terms, a term with weight 0 is not computed at all.
+//Board terms count the pieces matching a pattern for each side, move terms are added for the best move
+//of each side and scaled by BestMove.
+type Weights struct {
+	Piece       int `json:"piece"`
+	King        int `json:"king"`
+	GoldenStone int `json:"goldenStone"`
+	MiddleBox   int `json:"middleBox"`
+	Middle      int `json:"middle"`
+	LeftSide    int `json:"leftSide"`
+	RightSide   int `json:"rightSide"`
+	Protection  int `json:"protection"`
+	StuckPiece  int `json:"stuckPiece"`
+	StuckKing   int `json:"stuckKing"`
+	//LeggardAndGrape counts pieces left behind on the back rows
+	LeggardAndGrape int `json:"leggardAndGrape"`
+	FullSquare      int `json:"fullSquare"`
+	HalfSquare      int `json:"halfSquare"`
+	FullGate        int `json:"fullGate"`
+	HalfGate        int `json:"halfGate"`
+	Pincer          int `json:"pincer"`
+	LargestField    int `json:"largestField"`
+	VulnerablePiece int `json:"vulnerablePiece"`
+	SuicidalPiece   int `json:"suicidalPiece"`
+	//SavingMove is added for a move that saves a piece from being taken
+	SavingMove     int `json:"savingMove"`
+	ProtectingMove int `json:"protectingMove"`
+	KingMove       int `json:"kingMove"`
+	WinningMove    int `json:"winningMove"`
+	//Capture is added for a capture and CaptureStep for every step of its capture chain
+	Capture     int `json:"capture"`
+	CaptureStep int `json:"captureStep"`
+	//TakenMove is added for a move whose piece can be taken afterwards
+	TakenMove       int `json:"takenMove"`
+	LooseProtection int `json:"looseProtection"`
+	//BestMove scales the weight of the best move of each side
+	BestMove int `json:"bestMove"`
+}
+
+//DefaultWeights are the weights the engines play with unless configured otherwise
+var DefaultWeights = Weights{
+	Piece:           2 * pieceBaseVaue,
+	King:            15 * pieceBaseVaue,
+	GoldenStone:     3,
+	MiddleBox:       3,
+	Middle:          2,
+	LeftSide:        1,
+	RightSide:       1,
+	Protection:      4,
+	FullSquare:      5,
+	HalfSquare:      3,
+	FullGate:        2,
+	HalfGate:        1,
+	Pincer:          2,
+	LargestField:    1,
+	SavingMove:      14 * pieceBaseVaue,
+	ProtectingMove:  16 * pieceBaseVaue,
+	KingMove:        20 * pieceBaseVaue,
+	WinningMove:     1000,
+	Capture:         51 * pieceBaseVaue,
+	CaptureStep:     1,
+	TakenMove:       -99 * pieceBaseVaue,
+	LooseProtection: -15 * pieceBaseVaue,
+	BestMove:        2,
+}
+
+//weightProfiles are the named weights that can be chosen instead of a file
+var weightProfiles = map[string]Weights{
+	"default": DefaultWeights,
+	//aggressive values material and captures and cares less about the formation
+	"aggressive": DefaultWeights.with(func(w *Weights) {
+		w.King = 20
+		w.Protection = 2
+		w.FullSquare = 2
+		w.HalfSquare = 1
+		w.Capture = 80
+		w.CaptureStep = 10
+		w.TakenMove = -60
+	}),
+	//positional keeps the pieces together and the center occupied
+	"positional": DefaultWeights.with(func(w *Weights) {
+		w.MiddleBox = 6
+		w.Middle = 4
+		w.Protection = 6
+		w.FullSquare = 8
+		w.HalfSquare = 5
+		w.FullGate = 4
+		w.LargestField = 3
+		w.LeggardAndGrape = -1
+		w.VulnerablePiece = -5
+	}),
+}
+
+//with returns a copy of the weights changed by fn
+func (w Weights) with(fn func(*Weights)) Weights {
+	fn(&w)
+	return w
+}
+
+//WeightProfiles returns the names of the built in weight profiles
+func WeightProfiles() []string {
+	names := make([]string, 0, len(weightProfiles))
+	for name := range weightProfiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+//WeightProfile returns the built in weights with the given name
+func WeightProfile(name string) (Weights, error) {
+	w, ok := weightProfiles[name]
+	if !ok {
+		return Weights{}, fmt.Errorf("unknown weight profile %q, choose one of %s", name, strings.Join(WeightProfiles(), ", "))
+	}
+	return w, nil
+}
+
+//ReadWeights decodes weights from JSON, weights missing in the JSON keep their default value
+func ReadWeights(r io.Reader) (Weights, error) {
+	w := DefaultWeights
+	dec := json.NewDecoder(r)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&w); err != nil {
+		return Weights{}, fmt.Errorf("invalid weights: %w", err)
+	}
+	return w, nil
+}
+
+//LoadWeights returns the built in profile with the given name or reads the weights from the JSON file at that path
+func LoadWeights(nameOrPath string) (Weights, error) {
+	if w, ok := weightProfiles[nameOrPath]; ok {
+		return w, nil
+	}
+	f, err := os.Open(nameOrPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return Weights{}, fmt.Errorf("%q is neither a weights file nor a weight profile (%s)", nameOrPath, strings.Join(WeightProfiles(), ", "))
+		}
+		return Weights{}, err
+	}
+	defer f.Close()
+	return ReadWeights(f)
+}
+
+//WriteJSON encodes the weights as indented JSON
+func (w Weights) WriteJSON(out io.Writer) error {
+	enc := json.NewEncoder(out)
+	enc.SetIndent("", "  ")
+	return enc.Encode(w)
+}
@@ -0,0 +1,67 @@
+package game
+
+import "math/rand"
+
+//zobristSeed is fixed so hashes are the same in every run (e.g. for opening books)
+const zobristSeed = 20211107
+
+//zobristPieces holds a random key per square and piece kind (white man, white king, red man, red king)
+var zobristPieces [100][4]uint64
+
+//zobristRed is mixed into the hash when red is to move
+var zobristRed uint64
+
+//zobristKingMoves and zobristEndgameMoves are mixed into the search key for the draw rule counters,
+//the keys of a counter at 0 are 0 so positions without a running counter keep their hash
+var zobristKingMoves [kingMovesLimit + 1]uint64
+var zobristEndgameMoves [endgameMovesLimit + 1]uint64
+
+func init() {
+	rng := rand.New(rand.NewSource(zobristSeed))
+	for i := range zobristPieces {
+		for k := range zobristPieces[i] {
+			zobristPieces[i][k] = rng.Uint64()
+		}
+	}
+	zobristRed = rng.Uint64()
+	//drawn after the piece keys so the position hashes (and the opening books using them) do not change
+	for i := 1; i < len(zobristKingMoves); i++ {
+		zobristKingMoves[i] = rng.Uint64()
+	}
+	for i := 1; i < len(zobristEndgameMoves); i++ {
+		zobristEndgameMoves[i] = rng.Uint64()
+	}
+}
+
+//pieceKind returns the zobrist index of the piece on the field
+func (f Field) pieceKind() int {
+	k := 0
+	if !f.isWhitePiece() {
+		k = 2
+	}
+	if f.isKing() {
+		k++
+	}
+	return k
+}
+
+//hash returns the zobrist hash of the position including the player to move
+func (b Board) hash(player bool) uint64 {
+	h := uint64(0)
+	for i, v := range b {
+		if !v.isEmpty() {
+			h ^= zobristPieces[i][v.pieceKind()]
+		}
+	}
+	if !player {
+		h ^= zobristRed
+	}
+	return h
+}
+
+//searchKey returns the hash of the position together with the draw rule counters,
+//the same position can score differently when a draw by the move counting rules is close
+func (b Board) searchKey(player bool, draws drawCounter) uint64 {
+	return b.hash(player) ^ zobristKingMoves[minOf(draws.kingMoves, kingMovesLimit)] ^
+		zobristEndgameMoves[minOf(draws.endgameMoves, endgameMovesLimit)]
+}
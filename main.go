@@ -1,10 +1,13 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"fmt"
 	"log"
 	"math"
+	"os"
+	"strings"
 	"time"
 
 	"github.com/faiface/pixel"
@@ -20,6 +23,11 @@ import (
 var fullAIMode = false
 var showEvalMode = false
 var showGridIndex = false
+var startPosition = ""
+var recordFile = ""
+var whiteEngine game.Engine
+var redEngine game.Engine
+var tablebase *game.Tablebase
 
 const moveSeconds = 0.3
 
@@ -38,6 +46,16 @@ func run() {
 	win.SetSmooth(true)
 	grid := imdraw.New(nil)
 	g := game.SetupGame()
+	if startPosition != "" {
+		board, player, err := game.ParseFEN(startPosition)
+		if err != nil {
+			panic(err)
+		}
+		g = game.SetupGameFromPosition(board, player)
+	}
+	g.SetEngine(true, whiteEngine)
+	g.SetEngine(false, redEngine)
+	g.SetTablebase(tablebase)
 	g.Start()
 	moves := []game.PossibleMove{}
 	selectedPiece := []game.PossibleMove{}
@@ -48,6 +66,27 @@ func run() {
 	atlas := text.NewAtlas(basicfont.Face7x13, text.ASCII)
 	overlayText := text.New(pixel.V(10, 10), atlas)
 	overlayText.Color = colornames.Magenta
+	termsText := text.New(pixel.V(10, 450), atlas)
+	termsText.Color = colornames.Magenta
+	showTerms := false
+	termsPosition := ""
+	forcedText := text.New(pixel.V(10, 580), atlas)
+	forcedText.Color = colornames.Magenta
+	thinkingText := text.New(pixel.V(10, 565), atlas)
+	thinkingText.Color = colornames.Magenta
+	recorded := false
+	//the running ai search, its progress is polled every frame so the window stays responsive
+	var aiResults <-chan game.AIResult
+	cancelAI := func() {}
+	var thinking game.SearchInfo
+	stopAI := func() {
+		cancelAI()
+		aiResults = nil
+		thinking = game.SearchInfo{}
+	}
+	defer func() { stopAI() }()
+	//aiFailed stops asking the ai for moves until a move is taken back or replayed
+	aiFailed := false
 	for !win.Closed() {
 		dt := time.Since(last).Seconds()
 		if moving > 0 {
@@ -57,43 +96,77 @@ func run() {
 			}
 		}
 		last = time.Now()
+		if !fullAIMode {
+			//take back the own move together with the ai reply
+			if win.JustPressed(pixelgl.KeyU) && g.TakeBack(true) {
+				stopAI()
+				aiFailed = false
+				moves = []game.PossibleMove{}
+				selectedPiece = []game.PossibleMove{}
+				moving = 0
+				recorded = false
+			}
+			if win.JustPressed(pixelgl.KeyR) && g.Redo() {
+				stopAI()
+				aiFailed = false
+				if !g.Player() {
+					g.Redo()
+				}
+				moves = []game.PossibleMove{}
+				selectedPiece = []game.PossibleMove{}
+			}
+		}
 		if g.GameState() == game.GameStateRunning {
 			if !g.HasBoardInQueue() {
-				if !g.Player() {
+				if !g.Player() || fullAIMode {
 					//ai
-					g.MakeAIMove()
-				} else {
-					if fullAIMode {
-						g.MakeAIMove()
-					} else {
-						if len(moves) == 0 {
-							moves = g.GetPossibleMoves()
+					if aiResults == nil && !aiFailed {
+						ctx, cancel := context.WithCancel(context.Background())
+						aiResults, cancelAI = g.StartAIMove(ctx), cancel
+					}
+					if aiResults != nil {
+						if done, err := pollAI(g, aiResults, &thinking); done {
+							stopAI()
+							if err != nil {
+								log.Printf("AI could not move: %v", err)
+								aiFailed = true
+							}
 						}
-						if win.JustPressed(pixelgl.MouseButtonLeft) {
-							vec := win.MousePosition()
-							col := math.Floor(vec.X / 60)
-							row := math.Floor((win.Bounds().H() - vec.Y) / 60)
-							if row < 10 && col < 10 {
-								for _, v := range selectedPiece {
-									if v.Move.To.Col == int(col) && v.Move.To.Row == int(row) {
-										g.MakeMove(v.Move)
-										moves = []game.PossibleMove{}
-										break
-									}
+					}
+				} else {
+					if len(moves) == 0 {
+						moves = g.GetPossibleMoves()
+					}
+					if win.JustPressed(pixelgl.MouseButtonLeft) {
+						vec := win.MousePosition()
+						col := math.Floor(vec.X / 60)
+						row := math.Floor((win.Bounds().H() - vec.Y) / 60)
+						if row < 10 && col < 10 {
+							for _, v := range selectedPiece {
+								if v.Move.To.Col == int(col) && v.Move.To.Row == int(row) {
+									g.MakeMove(v.Move)
+									moves = []game.PossibleMove{}
+									break
 								}
-								selectedPiece = []game.PossibleMove{}
-								for _, v := range moves {
-									if v.Path.Coordinates[0].Col == int(col) && v.Path.Coordinates[0].Row == int(row) {
-										selectedPiece = append(selectedPiece, v)
-									}
-
+							}
+							selectedPiece = []game.PossibleMove{}
+							for _, v := range moves {
+								if v.Path.Coordinates[0].Col == int(col) && v.Path.Coordinates[0].Row == int(row) {
+									selectedPiece = append(selectedPiece, v)
 								}
+
 							}
 						}
 					}
 				}
 			}
 		}
+		if recordFile != "" && !recorded && g.GameState() != game.GameStateRunning {
+			recorded = true
+			if err := writeRecord(g, recordFile); err != nil {
+				log.Printf("Could not write game record: %v", err)
+			}
+		}
 		grid.Clear()
 		mat := pixel.IM
 		mat = mat.Rotated(win.Bounds().Center(), -math.Pi/2)
@@ -116,6 +189,35 @@ func run() {
 			fmt.Fprintf(overlayText, "%d", g.CurrentEvaulation())
 			overlayText.Draw(win, pixel.IM)
 		}
+		if win.JustPressed(pixelgl.KeyE) {
+			showTerms = !showTerms
+		}
+		if showTerms {
+			//the breakdown is only recomputed when the position changes
+			if position := g.FEN(); position != termsPosition {
+				termsPosition = position
+				termsText.Clear()
+				fmt.Fprint(termsText, g.CurrentEvaluationDetails())
+			}
+			termsText.Draw(win, pixel.IM)
+		}
+		if !fullAIMode && len(moves) > 0 && moves[0].Captures > 0 {
+			forcedText.Clear()
+			if moves[0].Captures == 1 {
+				fmt.Fprint(forcedText, "Capture is forced")
+			} else {
+				fmt.Fprintf(forcedText, "Capture is forced, the most pieces (%d) have to be taken", moves[0].Captures)
+			}
+			forcedText.Draw(win, pixel.IM)
+		}
+		if aiResults != nil {
+			thinkingText.Clear()
+			fmt.Fprint(thinkingText, "Thinking...")
+			if thinking.Depth > 0 {
+				fmt.Fprintf(thinkingText, " depth %d, score %d, %d nodes/s\n%s", thinking.Depth, thinking.Score, thinking.NodesPerSecond, thinking.PVString())
+			}
+			thinkingText.Draw(win, pixel.IM)
+		}
 		if showGridIndex {
 			for i := 0; i < 10; i++ {
 				for j := 0; j < 10; j++ {
@@ -126,8 +228,10 @@ func run() {
 					} else {
 						indexText.Color = colornames.Darkgreen
 					}
-					fmt.Fprintf(indexText, "%d,%d", 9-j, i)
-					indexText.Draw(win, pixel.IM)
+					if square := (game.Coordinate{Row: 9 - j, Col: i}).Square(); square > 0 {
+						fmt.Fprintf(indexText, "%d", square)
+						indexText.Draw(win, pixel.IM)
+					}
 				}
 			}
 		}
@@ -136,6 +240,26 @@ func run() {
 	}
 }
 
+//pollAI reads the pending results of the ai search without blocking,
+//it plays the move once the search is done and reports if it is
+func pollAI(g *game.Game, results <-chan game.AIResult, thinking *game.SearchInfo) (bool, error) {
+	for {
+		select {
+		case r, ok := <-results:
+			if !ok {
+				return true, fmt.Errorf("ai search ended without a result")
+			}
+			if !r.Done {
+				*thinking = r.Info
+				continue
+			}
+			return true, g.PlayAIResult(r)
+		default:
+			return false, nil
+		}
+	}
+}
+
 func DrawBoard(imd *imdraw.IMDraw, board game.Board, moves []game.PossibleMove, hl []game.PossibleMove) {
 	cellSize := 60
 	for i := 0; i < 10; i++ {
@@ -227,16 +351,175 @@ func DrawBoard(imd *imdraw.IMDraw, board game.Board, moves []game.PossibleMove,
 
 }
 
+//engineConfig holds the engine flags
+type engineConfig struct {
+	depth       int
+	think       time.Duration
+	nodes       uint64
+	seed        int64
+	workers     int
+	weights     string
+	iterations  int
+	exploration float64
+	rollout     string
+	tablebase   *game.Tablebase
+	book        *game.Book
+}
+
+//newEngine creates the engine with the given name, it plays from the book while there is one
+func newEngine(name string, cfg engineConfig) (game.Engine, error) {
+	e, err := newSearchEngine(name, cfg)
+	if err != nil || cfg.book == nil {
+		return e, err
+	}
+	return game.NewBookEngine(e, cfg.book, cfg.seed), nil
+}
+
+//newSearchEngine creates the engine with the given name
+func newSearchEngine(name string, cfg engineConfig) (game.Engine, error) {
+	switch name {
+	case "minimax":
+		e := game.NewMinimaxEngine()
+		w, err := game.LoadWeights(cfg.weights)
+		if err != nil {
+			return nil, err
+		}
+		e.Weights = &w
+		//with a time or node limit the depth is only an upper bound (0 = none)
+		if cfg.think > 0 || cfg.nodes > 0 || cfg.depth > 0 {
+			e.Depth = cfg.depth
+		}
+		e.TimeLimit = cfg.think
+		e.NodeLimit = cfg.nodes
+		e.Seed = cfg.seed
+		e.Workers = cfg.workers
+		e.Tablebase = cfg.tablebase
+		return e, nil
+	case "mcts":
+		rollout, err := game.ParseRolloutPolicy(cfg.rollout)
+		if err != nil {
+			return nil, err
+		}
+		e := game.NewMCTSEngine(cfg.seed)
+		e.Iterations = cfg.iterations
+		e.TimeLimit = cfg.think
+		e.Exploration = cfg.exploration
+		e.Rollout = rollout
+		return e, nil
+	case "random":
+		seed := cfg.seed
+		if seed == 0 {
+			seed = time.Now().UnixNano()
+		}
+		return game.NewRandomEngine(seed), nil
+	}
+	return nil, fmt.Errorf("unknown engine %q (minimax, mcts, random)", name)
+}
+
+//loadBook reads the book of a side, the side's own file overrides the common one and none means no book
+func loadBook(path string, common string) (*game.Book, error) {
+	if path == "" {
+		path = common
+	}
+	if path == "" || path == "none" {
+		return nil, nil
+	}
+	return game.LoadBook(path)
+}
+
+//writeRecord writes the PDN record of the game to the given file
+func writeRecord(g *game.Game, path string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	white := "Human"
+	if fullAIMode {
+		white = "AI"
+	}
+	if err := g.WritePDN(f, map[string]string{"White": white, "Black": "AI"}); err != nil {
+		f.Close()
+		return err
+	}
+	log.Printf("Game record written to %s", path)
+	return f.Close()
+}
+
 func main() {
 	log.SetFlags(0)
 	log.SetOutput(new(logger))
 	aiMode := flag.Bool("ai", false, "full auto ai flag")
 	scoreMode := flag.Bool("s", false, "show score")
-	showIndex := flag.Bool("i", false, "show index")
+	showIndex := flag.Bool("i", false, "show square numbers")
+	fen := flag.String("fen", "", "start from the given FEN position")
+	pdn := flag.String("pdn", "", "write the PDN game record to this file when the game is over")
+	whiteName := flag.String("white", "minimax", "engine playing white in ai mode (minimax, mcts, random)")
+	redName := flag.String("red", "minimax", "engine playing red (minimax, mcts, random)")
+	depth := flag.Int("depth", 0, "maximum search depth of the minimax engine (default 4 without other limits)")
+	think := flag.Duration("think", 0, "thinking time per move of the minimax and mcts engines, e.g. 2s")
+	nodes := flag.Uint64("nodes", 0, "maximum searched nodes per move of the minimax engine")
+	seed := flag.Int64("seed", 0, "seed to choose among equally good moves, 0 plays deterministically (mcts: seed of its simulations)")
+	workers := flag.Int("workers", 1, "number of goroutines searching in parallel, more than 1 can change the choice among equally scored moves")
+	profiles := strings.Join(game.WeightProfiles(), ", ")
+	weights := flag.String("weights", "default", "evaluation weights of the minimax engines, a profile ("+profiles+") or a JSON file")
+	whiteWeights := flag.String("white-weights", "", "evaluation weights of the white minimax engine, overrides -weights")
+	redWeights := flag.String("red-weights", "", "evaluation weights of the red minimax engine, overrides -weights")
+	iterations := flag.Int("iterations", 0, "simulated games per move of the mcts engine (default 10000 without -think)")
+	exploration := flag.Float64("exploration", 0, "exploration constant of the mcts engine (default sqrt(2))")
+	rollout := flag.String("rollout", "random", "move choice in the simulated games of the mcts engine (random, heuristic)")
+	tablebaseFile := flag.String("tablebase", "", "endgame tablebase of the minimax engines and to end proven draws, see cmd/tablebase")
+	bookFile := flag.String("book", "", "opening book of both engines, see cmd/book")
+	whiteBook := flag.String("white-book", "", "opening book of the white engine, overrides -book (none turns it off)")
+	redBook := flag.String("red-book", "", "opening book of the red engine, overrides -book (none turns it off)")
 	flag.Parse()
 	fullAIMode = *aiMode
 	showEvalMode = *scoreMode
 	showGridIndex = *showIndex
+	startPosition = *fen
+	recordFile = *pdn
+	if *whiteWeights == "" {
+		whiteWeights = weights
+	}
+	if *redWeights == "" {
+		redWeights = weights
+	}
+	if *tablebaseFile != "" {
+		t, err := game.LoadTablebase(*tablebaseFile)
+		if err != nil {
+			log.Fatal(err)
+		}
+		tablebase = t
+	}
+	cfg := engineConfig{
+		depth:       *depth,
+		think:       *think,
+		nodes:       *nodes,
+		seed:        *seed,
+		workers:     *workers,
+		weights:     *whiteWeights,
+		iterations:  *iterations,
+		exploration: *exploration,
+		rollout:     *rollout,
+		tablebase:   tablebase,
+	}
+	var err error
+	if cfg.book, err = loadBook(*whiteBook, *bookFile); err != nil {
+		log.Fatal(err)
+	}
+	if whiteEngine, err = newEngine(*whiteName, cfg); err != nil {
+		log.Fatal(err)
+	}
+	//both sides get their own seed so they do not mirror each others choices
+	if cfg.seed != 0 {
+		cfg.seed++
+	}
+	cfg.weights = *redWeights
+	if cfg.book, err = loadBook(*redBook, *bookFile); err != nil {
+		log.Fatal(err)
+	}
+	if redEngine, err = newEngine(*redName, cfg); err != nil {
+		log.Fatal(err)
+	}
 	log.Printf("AI only mode: %v", fullAIMode)
 	pixelgl.Run(run)
 }